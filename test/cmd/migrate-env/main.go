@@ -0,0 +1,61 @@
+// migrate-env rewrites deprecated ZEEBE_* entries of orchestration.env in values files to their CAMUNDA_* equivalents
+//
+// For every values file it writes an overlay holding the migrated orchestration.env next to the source and prints a report
+// of the renamed, dropped and conflicting entries as well as deprecated entries without known equivalent.
+//
+//	go run ./cmd/migrate-env [-dry-run] [-suffix -migrated] values.yml...
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	valuesHelpers "multiregiontests/internal/helpers/values"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "only print the report, don't write overlays")
+	suffix := flag.String("suffix", "-migrated", "suffix of the overlay written next to each values file")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: migrate-env [-dry-run] [-suffix -migrated] values.yml...")
+		os.Exit(2)
+	}
+
+	failed := false
+	for _, path := range flag.Args() {
+		overlay, report, err := valuesHelpers.MigrateEnvFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed = true
+			continue
+		}
+
+		fmt.Print(report)
+		if overlay == nil || *dryRun {
+			continue
+		}
+
+		target := overlayPath(path, *suffix)
+		if err := os.WriteFile(target, overlay, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("  wrote %s\n", target)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// overlayPath returns values-migrated.yml for values.yml
+func overlayPath(path, suffix string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + suffix + ext
+}
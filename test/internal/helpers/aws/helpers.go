@@ -18,7 +18,6 @@ import (
 	eks_types "github.com/aws/aws-sdk-go-v2/service/eks/types"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/gruntwork-io/terratest/modules/k8s"
-	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/require"
 )
@@ -143,7 +142,8 @@ func GetPrivateIPsForInternalLB(region, description string) []string {
 	return privateIPs
 }
 
-func CreateLoadBalancers(t *testing.T, source helpers.Cluster, k8sManifests string) {
+// CreateLoadBalancers exposes CoreDNS of the cluster through an internal load balancer and returns its private IPs
+func CreateLoadBalancers(t *testing.T, source helpers.Cluster, k8sManifests string) []string {
 	t.Logf("[LOAD BALANCER] Creating load balancer for source cluster %s", source.ClusterName)
 
 	kubeResourcePath := fmt.Sprintf("%s/%s", k8sManifests, "internal-dns-lb.yml")
@@ -165,120 +165,53 @@ func CreateLoadBalancers(t *testing.T, source helpers.Cluster, k8sManifests stri
 	require.Greater(t, len(privateIPs), 1)
 
 	t.Logf("[LOAD BALANCER] Private IPs: %v", privateIPs)
-}
-func DNSChaining(t *testing.T, sourceCluster, targetCluster helpers.Cluster, k8sManifestsPath, primaryNamespaces, secondaryNamespaces string) {
-	// Split the namespace arrays
-	primaryNamespacesArr := strings.Split(primaryNamespaces, ",")
-	secondaryNamespacesArr := strings.Split(secondaryNamespaces, ",")
-
-	// Ensure both arrays have the same length
-	if len(primaryNamespacesArr) != len(secondaryNamespacesArr) {
-		t.Fatalf("Namespace arrays must have the same length")
-		return
-	}
-
-	var sourceClusterDnsEntries string
-	var targetClusterDnsEntries string
-
-	for i := 0; i < len(primaryNamespacesArr); i++ {
-		primaryNamespace := primaryNamespacesArr[i]
-		secondaryNamespace := secondaryNamespacesArr[i]
-
-		// Set environment variables for the script
-		os.Setenv("CLUSTER_0", sourceCluster.ClusterName)
-		os.Setenv("CLUSTER_1", targetCluster.ClusterName)
-		os.Setenv("CAMUNDA_NAMESPACE_0", primaryNamespace)
-		os.Setenv("CAMUNDA_NAMESPACE_1", secondaryNamespace)
-		os.Setenv("REGION_0", sourceCluster.Region)
-		os.Setenv("REGION_1", targetCluster.Region)
-
-		// Run the script and capture its output
-		output := shell.RunCommandAndGetOutput(t, shell.Command{
-			Command: "sh",
-			Args: []string{
-				"../aws/dual-region/scripts/generate_core_dns_entry.sh",
-			},
-		})
 
-		t.Logf("[DNS CHAINING] Output from script: %s", output)
-
-		// Extract the replacement text for "Cluster 0"
-		sourceClusterDNSEntry := extractReplacementText(output, "Cluster 0")
-		t.Logf("[DNS CHAINING] Replacement text for Cluster 0: %s", sourceClusterDNSEntry)
+	return privateIPs
+}
 
-		// Accumulate the replacement texts with proper indentation
-		sourceClusterDnsEntries += formatReplacementText(sourceClusterDNSEntry)
+// DNSChaining configures CoreDNS of every region to forward the namespaces of all other regions to their internal load balancer
+// lbIPs and namespaces are indexed by region id, namespaces lists all Camunda namespaces of a region
+func DNSChaining(t *testing.T, clusters helpers.Clusters, lbIPs [][]string, k8sManifestsPath string, namespaces [][]string) {
+	require.Len(t, lbIPs, len(clusters), "Load balancer IPs are required for every region")
+	require.Len(t, namespaces, len(clusters), "Namespaces are required for every region")
+
+	for target := range clusters {
+		var entries []string
+		for source := range clusters {
+			if source == target {
+				continue
+			}
+			for _, namespace := range namespaces[source] {
+				if namespace == "" {
+					continue
+				}
+				entries = append(entries, helpers.CoreDNSForwardEntry(namespace, lbIPs[source]))
+			}
+		}
 
-		// Extract the replacement text for "Cluster 1"
-		targetClusterDNSEntry := extractReplacementText(output, "Cluster 1")
-		t.Logf("[DNS CHAINING] Replacement text for Cluster 1: %s", targetClusterDNSEntry)
+		dnsEntries := strings.Join(entries, "\n")
+		t.Logf("[DNS CHAINING] CoreDNS entries for %s:\n%s", clusters[target].ClusterName, dnsEntries)
 
-		// Accumulate the replacement texts with proper indentation
-		targetClusterDnsEntries += formatReplacementText(targetClusterDNSEntry)
+		// Generate and apply the CoreDNS manifest
+		generateAndApplyCoreDNSManifest(t, clusters[target], k8sManifestsPath, dnsEntries)
 	}
-
-	// Generate and apply the CoreDNS manifest
-	generateAndApplyCoreDNSManifest(t, sourceCluster, k8sManifestsPath, sourceClusterDnsEntries)
-	generateAndApplyCoreDNSManifest(t, targetCluster, k8sManifestsPath, targetClusterDnsEntries)
-
 }
 
 func generateAndApplyCoreDNSManifest(t *testing.T, targetCluster helpers.Cluster, k8sManifests, dnsEntries string) {
-	// Replace the placeholder text in the existing CoreDNS ConfigMap
+	// Replace the placeholder text of the CoreDNS ConfigMap in a temporary copy, the manifest stays untouched for the other clusters
 	t.Logf("[DNS CHAINING] Replacing PLACEHOLDER in CoreDNS ConfigMap with generated replacement text")
 	filePath := fmt.Sprintf("%s/%s", k8sManifests, "coredns.yml")
 	content, err := os.ReadFile(filePath)
-	if err != nil {
-		t.Fatalf("Error reading file: %v\n", err)
-		return
-	}
-
-	// Convert byte slice to string
-	fileContent := string(content)
+	require.NoError(t, err, "[DNS CHAINING] Failed to read %s", filePath)
 
-	// Replace the placeholder with the accumulated replacement string
-	modifiedContent := strings.Replace(fileContent, "PLACEHOLDER", dnsEntries, -1)
+	// Replace the placeholder with the accumulated replacement string, indented like the placeholder in the Corefile
+	modifiedContent := strings.Replace(string(content), "PLACEHOLDER", strings.ReplaceAll(dnsEntries, "\n", "\n        "), -1)
 
-	// Write the modified content back to the file
-	err = os.WriteFile(filePath, []byte(modifiedContent), 0644)
-	if err != nil {
-		t.Fatalf("Error writing file: %v\n", err)
-		return
-	}
+	overlay, err := helpers.RenderOverlay(t, filePath, modifiedContent)
+	require.NoError(t, err, "[DNS CHAINING] Failed to render %s", filePath)
 
 	// Apply the CoreDNS change to the target cluster to let it know how to reach the source cluster
-	k8s.KubectlApply(t, &targetCluster.KubectlSystem, filePath)
-
-	t.Log("[DNS CHAINING] Writing Placeholder CoreDNS ConfigMap back to file")
-	// Write the old file back to the file - required for bidirectional communication
-	err = os.WriteFile(filePath, []byte(fileContent), 0644)
-	if err != nil {
-		t.Fatalf("Error writing file: %v\n", err)
-		return
-	}
-}
-
-func extractReplacementText(output, clusterMarker string) string {
-	startMarker := fmt.Sprintf("### %s - Start ###\n", clusterMarker)
-	endMarker := fmt.Sprintf("\n### %s - End ###", clusterMarker)
-	startIndex := strings.Index(output, startMarker)
-	if startIndex == -1 {
-		return ""
-	}
-	startIndex += len(startMarker)
-	endIndex := strings.Index(output[startIndex:], endMarker)
-	if endIndex == -1 {
-		return ""
-	}
-	return strings.TrimSpace(output[startIndex : startIndex+endIndex])
-}
-
-func formatReplacementText(replacement string) string {
-	lines := strings.Split(replacement, "\n")
-	for i := range lines {
-		lines[i] = "    " + lines[i]
-	}
-	return strings.Join(lines, "\n")
+	k8s.KubectlApply(t, &targetCluster.KubectlSystem, overlay)
 }
 
 func ClusterReadyCheck(t *testing.T, cluster helpers.Cluster) {
@@ -6,6 +6,7 @@ import (
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/gruntwork-io/terratest/modules/k8s"
@@ -21,6 +22,10 @@ type Cluster struct {
 	KubectlNamespace k8s.KubectlOptions
 	KubectlSystem    k8s.KubectlOptions
 	KubectlFailover  k8s.KubectlOptions
+	// RegionID is the Camunda region id, global.multiregion.regionId, independent of the cloud region
+	RegionID int
+	// ValuesFile holds the region specific Helm values, empty if the region has none
+	ValuesFile string
 }
 
 // Go Helpers
@@ -41,6 +46,8 @@ func IsTeleportEnabled() bool {
 	return boolVal
 }
 
+// CutOutString returns the number matched by searchString in originalString
+// If searchString contains a capture group, its last group is parsed, otherwise the trailing digits of the match
 func CutOutString(originalString, searchString string) int {
 	re := regexp.MustCompile(searchString)
 	matches := re.FindStringSubmatch(originalString)
@@ -49,12 +56,15 @@ func CutOutString(originalString, searchString string) int {
 		return -1
 	}
 
-	length := len(matches[0])
-	if (length) == 0 {
+	match := matches[len(matches)-1]
+	if len(matches) == 1 {
+		match = match[strings.LastIndexFunc(match, func(r rune) bool { return r < '0' || r > '9' })+1:]
+	}
+	if (len(match)) == 0 {
 		return -1
 	}
 
-	num, err := strconv.Atoi(string(matches[0][length-1]))
+	num, err := strconv.Atoi(match)
 	if err != nil {
 		return -1
 	}
@@ -62,22 +72,6 @@ func CutOutString(originalString, searchString string) int {
 	return num
 }
 
-func IsEven(num int) bool {
-	if num < 0 {
-		return false
-	}
-
-	return num%2 == 0
-}
-
-func IsOdd(num int) bool {
-	if num < 0 {
-		return false
-	}
-
-	return num%2 == 1
-}
-
 // Terraform Helpers
 func FetchSensitiveTerraformOutput(t *testing.T, options *terraform.Options, name string) string {
 	defer func() {
@@ -0,0 +1,27 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCutOutString(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		input  string
+		search string
+		want   int
+	}{
+		{"single digit", "HOME=/\x00ORCHESTRATION_NODE_ID=3\x00PATH=/bin", "ORCHESTRATION_NODE_ID=([0-9]+)", 3},
+		{"two digits", "HOME=/\x00ORCHESTRATION_NODE_ID=10\x00PATH=/bin", "ORCHESTRATION_NODE_ID=([0-9]+)", 10},
+		{"last entry", "HOME=/\x00ORCHESTRATION_NODE_ID=17", "ORCHESTRATION_NODE_ID=([0-9]+)", 17},
+		{"three digits", "ORCHESTRATION_NODE_ID=128\x00", "ORCHESTRATION_NODE_ID=([0-9]+)", 128},
+		{"without group", "ORCHESTRATION_NODE_ID=12\x00", "ORCHESTRATION_NODE_ID=[0-9]+", 12},
+		{"no match", "HOME=/\x00PATH=/bin", "ORCHESTRATION_NODE_ID=([0-9]+)", -1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, CutOutString(tc.input, tc.search))
+		})
+	}
+}
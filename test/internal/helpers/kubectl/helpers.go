@@ -3,19 +3,26 @@ package kubectlHelpers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"multiregiontests/internal/helpers"
+	valuesHelpers "multiregiontests/internal/helpers/values"
+	versionHelpers "multiregiontests/internal/helpers/versions"
 
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/gruntwork-io/terratest/modules/helm"
@@ -52,6 +59,9 @@ type ClusterInfo struct {
 	GatewayVersion    string   `json:"gatewayVersion"`
 }
 
+// ErrStatefulSetNotFound is returned when a StatefulSet does not exist in the namespace
+var ErrStatefulSetNotFound = errors.New("statefulset not found")
+
 type ElasticsearchClusterHealth struct {
 	ClusterName string `json:"cluster_name"`
 	Status      string `json:"status"`
@@ -77,6 +87,46 @@ func NewServiceTunnelWithRetry(t *testing.T, kubectlOptions *k8s.KubectlOptions,
 	svc := k8s.GetService(t, kubectlOptions, serviceName)
 	require.Equal(t, serviceName, svc.Name)
 
+	return newTunnelWithRetry(t, kubectlOptions, k8s.ResourceTypeService, serviceName, localPort, remotePort, maxRetries, backoff)
+}
+
+// NewServiceTunnelWithRetryE is NewServiceTunnelWithRetry returning the error instead of failing the test
+func NewServiceTunnelWithRetryE(t *testing.T, kubectlOptions *k8s.KubectlOptions, serviceName string, localPort, remotePort, maxRetries int, backoff time.Duration) (string, func(), error) {
+	t.Helper()
+
+	if _, err := k8s.GetServiceE(t, kubectlOptions, serviceName); err != nil {
+		return "", func() {}, err
+	}
+	return newTunnelWithRetryE(t, kubectlOptions, k8s.ResourceTypeService, serviceName, localPort, remotePort, maxRetries, backoff)
+}
+
+// NewPodTunnelWithRetry establishes a port-forward tunnel to a single Pod with retry logic.
+// Same as NewServiceTunnelWithRetry, used to reach a specific broker instead of any pod behind a Service.
+func NewPodTunnelWithRetry(t *testing.T, kubectlOptions *k8s.KubectlOptions, podName string, localPort, remotePort, maxRetries int, backoff time.Duration) (string, func()) {
+	t.Helper()
+
+	// Ensure pod exists early (gives clearer error)
+	pod := k8s.GetPod(t, kubectlOptions, podName)
+	require.Equal(t, podName, pod.Name)
+
+	return newTunnelWithRetry(t, kubectlOptions, k8s.ResourceTypePod, podName, localPort, remotePort, maxRetries, backoff)
+}
+
+func newTunnelWithRetry(t *testing.T, kubectlOptions *k8s.KubectlOptions, resourceType k8s.KubeResourceType, resourceName string, localPort, remotePort, maxRetries int, backoff time.Duration) (string, func()) {
+	t.Helper()
+
+	endpoint, cleanup, err := newTunnelWithRetryE(t, kubectlOptions, resourceType, resourceName, localPort, remotePort, maxRetries, backoff)
+	if err != nil {
+		t.Fatalf("[TUNNEL] %v", err)
+		return "", func() {}
+	}
+	return endpoint, cleanup
+}
+
+// newTunnelWithRetryE is newTunnelWithRetry returning the error instead of failing the test
+func newTunnelWithRetryE(t *testing.T, kubectlOptions *k8s.KubectlOptions, resourceType k8s.KubeResourceType, resourceName string, localPort, remotePort, maxRetries int, backoff time.Duration) (string, func(), error) {
+	t.Helper()
+
 	if maxRetries < 1 {
 		maxRetries = 1
 	}
@@ -84,81 +134,93 @@ func NewServiceTunnelWithRetry(t *testing.T, kubectlOptions *k8s.KubectlOptions,
 		backoff = 5 * time.Second
 	}
 
-	tunnel := k8s.NewTunnel(kubectlOptions, k8s.ResourceTypeService, serviceName, localPort, remotePort)
+	tunnel := k8s.NewTunnel(kubectlOptions, resourceType, resourceName, localPort, remotePort)
 	var err error
 	for i := 0; i < maxRetries; i++ {
 		err = tunnel.ForwardPortE(t)
 		if err == nil {
 			break
 		}
-		t.Logf("[TUNNEL] port-forward attempt %d/%d failed for %s:%d -> %s: %v", i+1, maxRetries, serviceName, remotePort, kubectlOptions.Namespace, err)
+		t.Logf("[TUNNEL] port-forward attempt %d/%d failed for %s:%d -> %s: %v", i+1, maxRetries, resourceName, remotePort, kubectlOptions.Namespace, err)
 		if i < maxRetries-1 {
 			time.Sleep(backoff)
 		}
 	}
 	if err != nil {
-		t.Fatalf("[TUNNEL] failed to establish port-forward after %d attempts: %v", maxRetries, err)
-		return "", func() {}
+		return "", func() {}, fmt.Errorf("failed to establish port-forward after %d attempts: %w", maxRetries, err)
 	}
 
 	cleanup := func() { tunnel.Close() }
-	return tunnel.Endpoint(), cleanup
+	return tunnel.Endpoint(), cleanup, nil
 }
 
-func CrossClusterCommunication(t *testing.T, withDNS bool, k8sManifests string, primary, secondary helpers.Cluster, kubeConfigPrimary, kubeConfigSecondary string) {
+// CrossClusterCommunication verifies every region can reach every other region, either through DNS or the pod IPs directly
+// kubeConfigs is indexed by region id like clusters
+func CrossClusterCommunication(t *testing.T, withDNS bool, k8sManifests string, clusters helpers.Clusters, kubeConfigs []string) {
 	kubeResourcePath := fmt.Sprintf("%s/%s", k8sManifests, "nginx.yml")
 
 	if withDNS {
-		primaryNamespaceArr := strings.Split(helpers.GetEnv("CLUSTER_0_NAMESPACE_ARR", ""), ",")
-		secondaryNamespaceArr := strings.Split(helpers.GetEnv("CLUSTER_1_NAMESPACE_ARR", ""), ",")
-		for i := 0; i < len(primaryNamespaceArr); i++ {
-			os.Setenv("CLUSTER_0", primary.ClusterName)
-			os.Setenv("CAMUNDA_NAMESPACE_0", primaryNamespaceArr[i])
-			os.Setenv("CLUSTER_1", secondary.ClusterName)
-			os.Setenv("CAMUNDA_NAMESPACE_1", secondaryNamespaceArr[i])
-			os.Setenv("KUBECONFIG", kubeConfigPrimary+":"+kubeConfigSecondary)
-
-			output := shell.RunCommandAndGetOutput(t, shell.Command{
-				Command: "sh",
-				Args: []string{
-					"../aws/dual-region/scripts/test_dns_chaining.sh",
-				},
-			})
+		namespaceArrs := make([][]string, len(clusters))
+		for region := range clusters {
+			namespaceArrs[region] = strings.Split(helpers.GetEnv(fmt.Sprintf("CLUSTER_%d_NAMESPACE_ARR", region), ""), ",")
+		}
 
-			// Check the output for success or failure messages
-			if strings.Contains(output, "Failed to reach the target instance") {
-				t.Fatalf("Script failed: %s", output)
-			} else {
-				t.Logf("Script output: %s", output)
+		// the script checks a pair of regions, so every ordered pair is checked once
+		for source := range clusters {
+			for target := range clusters {
+				if source == target {
+					continue
+				}
+				for i := 0; i < len(namespaceArrs[source]) && i < len(namespaceArrs[target]); i++ {
+					os.Setenv("CLUSTER_0", clusters[source].ClusterName)
+					os.Setenv("CAMUNDA_NAMESPACE_0", namespaceArrs[source][i])
+					os.Setenv("CLUSTER_1", clusters[target].ClusterName)
+					os.Setenv("CAMUNDA_NAMESPACE_1", namespaceArrs[target][i])
+					os.Setenv("KUBECONFIG", kubeConfigs[source]+":"+kubeConfigs[target])
+
+					output := shell.RunCommandAndGetOutput(t, shell.Command{
+						Command: "sh",
+						Args: []string{
+							"../aws/dual-region/scripts/test_dns_chaining.sh",
+						},
+					})
+
+					// Check the output for success or failure messages
+					if strings.Contains(output, "Failed to reach the target instance") {
+						t.Fatalf("Script failed: %s", output)
+					} else {
+						t.Logf("Script output: %s", output)
+					}
+				}
 			}
 		}
 
 	} else {
 		// Check if the pods can reach each other via the IPs directly
 
-		defer k8s.KubectlDelete(t, &primary.KubectlNamespace, kubeResourcePath)
-		defer k8s.KubectlDelete(t, &secondary.KubectlNamespace, kubeResourcePath)
-
-		k8s.KubectlApply(t, &primary.KubectlNamespace, kubeResourcePath)
-		k8s.KubectlApply(t, &secondary.KubectlNamespace, kubeResourcePath)
-
-		k8s.WaitUntilServiceAvailable(t, &primary.KubectlNamespace, "sample-nginx-peer", 10, 5*time.Second)
-		k8s.WaitUntilServiceAvailable(t, &secondary.KubectlNamespace, "sample-nginx-peer", 10, 5*time.Second)
-
-		k8s.WaitUntilPodAvailable(t, &primary.KubectlNamespace, "sample-nginx", 10, 5*time.Second)
-		k8s.WaitUntilPodAvailable(t, &secondary.KubectlNamespace, "sample-nginx", 10, 5*time.Second)
+		podIPs := make([]string, len(clusters))
+		podNames := make([]string, len(clusters))
+		for region := range clusters {
+			options := &clusters[region].KubectlNamespace
+			defer k8s.KubectlDelete(t, options, kubeResourcePath)
 
-		podPrimary := k8s.GetPod(t, &primary.KubectlNamespace, "sample-nginx")
-		podSecondary := k8s.GetPod(t, &secondary.KubectlNamespace, "sample-nginx")
+			k8s.KubectlApply(t, options, kubeResourcePath)
+			k8s.WaitUntilServiceAvailable(t, options, "sample-nginx-peer", 10, 5*time.Second)
+			k8s.WaitUntilPodAvailable(t, options, "sample-nginx", 10, 5*time.Second)
 
-		podPrimaryIP := podPrimary.Status.PodIP
-		require.NotEmpty(t, podPrimaryIP)
-
-		podSecondaryIP := podSecondary.Status.PodIP
-		require.NotEmpty(t, podSecondaryIP)
+			pod := k8s.GetPod(t, options, "sample-nginx")
+			require.NotEmpty(t, pod.Status.PodIP)
+			podIPs[region] = pod.Status.PodIP
+			podNames[region] = pod.Name
+		}
 
-		k8s.RunKubectl(t, &primary.KubectlNamespace, "exec", podPrimary.Name, "--", "curl", "--max-time", "15", podSecondaryIP)
-		k8s.RunKubectl(t, &secondary.KubectlNamespace, "exec", podSecondary.Name, "--", "curl", "--max-time", "15", podPrimaryIP)
+		for source := range clusters {
+			for target := range clusters {
+				if source != target {
+					k8s.RunKubectl(t, &clusters[source].KubectlNamespace, "exec", podNames[source], "--", "curl", "--max-time", "15", podIPs[target])
+				}
+			}
+		}
 
 		t.Log("[CROSS CLUSTER COMMUNICATION] Communication established")
 	}
@@ -321,6 +383,48 @@ func CheckOperateForProcessInstances(t *testing.T, cluster helpers.Cluster, size
 	require.Contains(t, bodyString, fmt.Sprintf("\"totalItems\":%d", size))
 }
 
+// CountProcessInstancesE returns the number of process instances the cluster reports, used to tell whether instances were already started
+func CountProcessInstancesE(t *testing.T, cluster helpers.Cluster, tenantId string) (int, error) {
+	endpoint, closeFn, err := newTunnelWithRetryE(t, &cluster.KubectlNamespace, k8s.ResourceTypeService, "camunda-zeebe-gateway", 0, 8080, 5, 10*time.Second)
+	if err != nil {
+		return 0, err
+	}
+	defer closeFn()
+
+	instanceRequestBody := `{}`
+	if tenantId != "" {
+		instanceRequestBody = fmt.Sprintf(`{"filter": { "tenantId":"%s" }}`, tenantId)
+	}
+
+	code, body, err := http_helper.HTTPDoWithOptionsE(t, http_helper.HttpDoOptions{
+		Method: "POST",
+		Url:    fmt.Sprintf("http://%s/v2/process-instances/search", endpoint),
+		Body:   strings.NewReader(instanceRequestBody),
+		Headers: map[string]string{
+			"Content-Type":  "application/json",
+			"Authorization": basicAuthDemoHeader,
+		},
+		TlsConfig: nil,
+		Timeout:   30,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if code != 200 {
+		return 0, fmt.Errorf("unexpected status %d: %s", code, body)
+	}
+
+	var result struct {
+		Page struct {
+			TotalItems int `json:"totalItems"`
+		} `json:"page"`
+	}
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		return 0, fmt.Errorf("failed to parse process instances response: %w", err)
+	}
+	return result.Page.TotalItems, nil
+}
+
 func RunSensitiveKubectlCommand(t *testing.T, kubectlOptions *k8s.KubectlOptions, command ...string) {
 	defer func() {
 		kubectlOptions.Logger = nil
@@ -419,74 +523,75 @@ func RestoreElasticBackup(t *testing.T, cluster helpers.Cluster, backupName stri
 
 }
 
-func InstallUpgradeC8Helm(t *testing.T, kubectlOptions *k8s.KubectlOptions, remoteChartVersion, remoteChartName, remoteChartSource, namespace0, namespace1 string, valuesYamlFiles []string, region int, setValues, setStringValues map[string]string) {
-
-	if !helpers.IsTeleportEnabled() {
-		// Set environment variables for the script
-		os.Setenv("CAMUNDA_NAMESPACE_0", namespace0)
-		os.Setenv("CAMUNDA_NAMESPACE_1", namespace1)
-		os.Setenv("CAMUNDA_RELEASE_NAME", "camunda")
-		os.Setenv("ZEEBE_CLUSTER_SIZE", "8")
-	}
-
-	// Run the script and capture its output
-	cmd := exec.Command("bash", "-c", "../aws/dual-region/scripts/generate_zeebe_helm_values.sh")
-	output, err := cmd.Output()
-	if err != nil {
-		t.Fatalf("[C8 HELM] Error running script: %v\n", err)
-		return
-	}
+// C8HelmRelease is the prepared upgrade of the Camunda release of a region
+type C8HelmRelease struct {
+	Cluster   helpers.Cluster
+	ChartName string
+	Options   *helm.Options
+}
 
-	// Convert byte slice to string
-	scriptOutput := string(output)
+// InstallUpgradeC8Helm installs or upgrades the Camunda release of the region
+// The initial contact points and the exporter URLs of all regions are generated from namespaces, indexed by region id
+func InstallUpgradeC8Helm(t *testing.T, cluster helpers.Cluster, remoteChartVersion, remoteChartName, remoteChartSource string, namespaces []string, clusterSize int, valuesYamlFiles []string, setValues, setStringValues map[string]string) {
+	release := PrepareC8Helm(t, cluster, remoteChartVersion, remoteChartName, remoteChartSource, namespaces, clusterSize, valuesYamlFiles, setValues, setStringValues)
+	require.NoError(t, release.UpgradeE(t), "[C8 HELM] Failed to upgrade %s", cluster.ClusterName)
+}
 
-	// Extract the replacement text for the initial contact points and Elasticsearch URLs
-	initialContact := extractReplacementText(scriptOutput, "CAMUNDA_CLUSTER_INITIALCONTACTPOINTS")
-	elastic0 := extractReplacementText(scriptOutput, "ZEEBE_BROKER_EXPORTERS_CAMUNDAREGION0_ARGS_CONNECT_URL")
-	elastic1 := extractReplacementText(scriptOutput, "ZEEBE_BROKER_EXPORTERS_CAMUNDAREGION1_ARGS_CONNECT_URL")
+// PrepareC8Helm renders the values and adds the chart repository for InstallUpgradeC8Helm, without upgrading the release yet
+func PrepareC8Helm(t *testing.T, cluster helpers.Cluster, remoteChartVersion, remoteChartName, remoteChartSource string, namespaces []string, clusterSize int, valuesYamlFiles []string, setValues, setStringValues map[string]string) C8HelmRelease {
 
-	require.NotEmpty(t, initialContact, "Initial contact points should not be empty")
-	require.NotEmpty(t, elastic0, "Elasticsearch region 0 URL should not be empty")
-	require.NotEmpty(t, elastic1, "Elasticsearch region 1 URL should not be empty")
+	capabilities, err := versionHelpers.ForChart(remoteChartVersion)
+	require.NoError(t, err, "[C8 HELM] Unknown chart version")
 
-	valuesFiles := valuesYamlFiles
+	config := valuesHelpers.Config{
+		Release:          "camunda",
+		Namespaces:       namespaces,
+		ClusterSize:      clusterSize,
+		ContactPointsEnv: capabilities.InitialContactPointsEnv(),
+		ExporterClass:    capabilities.ExporterClass(),
+	}
+	require.NoError(t, config.Validate(), "[C8 HELM] Invalid multi-region configuration")
+
+	valuesFiles := slices.Clone(valuesYamlFiles)
+
+	// The region values come last, so nothing overrides what is specific to the region
+	if cluster.ValuesFile != "" {
+		content, err := os.ReadFile(cluster.ValuesFile)
+		require.NoError(t, err, "[C8 HELM] Failed to read the values of region %d", cluster.RegionID)
+		regionId, ok, err := valuesHelpers.ValuesRegionId(content)
+		require.NoError(t, err, "[C8 HELM] Failed to read the values of region %d", cluster.RegionID)
+		require.True(t, !ok || regionId == cluster.RegionID, "[C8 HELM] %s sets regionId %d, but %s is region %d", cluster.ValuesFile, regionId, cluster.ClusterName, cluster.RegionID)
+		valuesFiles = append(valuesFiles, cluster.ValuesFile)
+	}
 
 	if helpers.IsTeleportEnabled() {
 		valuesFiles = append(valuesFiles, "./fixtures/teleport-affinities-tolerations.yml")
 	}
 
-	filePath := valuesFiles[0]
-
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		t.Fatalf("[C8 HELM] Error reading file: %v\n", err)
-		return
-	}
+	// The generated values are passed to Helm as an overlay after all values files, which stay untouched
+	values := valuesHelpers.NewBuilder()
+	require.NoError(t, values.LoadFiles(valuesFiles...), "[C8 HELM] Failed to read the values files")
+	overlay, err := config.ApplyOverlay(values, cluster.RegionID)
+	require.NoError(t, err, "[C8 HELM] Failed to generate the values of region %d", cluster.RegionID)
 
-	// Convert byte slice to string
-	fileContent := string(content)
+	rendered, err := values.Render()
+	require.NoError(t, err, "[C8 HELM] Failed to render the values of region %d", cluster.RegionID)
+	require.NotContains(t, string(rendered), valuesHelpers.Placeholder, "[C8 HELM] The values of region %d still contain %s", cluster.RegionID, valuesHelpers.Placeholder)
+	require.Empty(t, valuesHelpers.ExampleNamespaceReferences(string(rendered)), "[C8 HELM] The values of region %d reference the example namespaces", cluster.RegionID)
 
-	// Replace the placeholders with the replacement strings
-	modifiedContent := strings.Replace(fileContent, "PLACEHOLDER", initialContact, -1)
-	modifiedContent = strings.Replace(modifiedContent, "http://camunda-elasticsearch-master-hl.camunda-primary.svc.cluster.local:9200", elastic0, -1)
-	modifiedContent = strings.Replace(modifiedContent, "http://camunda-elasticsearch-master-hl.camunda-secondary.svc.cluster.local:9200", elastic1, -1)
-
-	// Write the modified content back to the file
-	err = os.WriteFile(filePath, []byte(modifiedContent), 0644)
-	if err != nil {
-		t.Fatalf("[C8 HELM] Error writing file: %v\n", err)
-		return
-	}
+	overlayFile, err := helpers.RenderOverlay(t, fmt.Sprintf("multi-region-%d.yml", cluster.RegionID), string(overlay))
+	require.NoError(t, err, "[C8 HELM] Failed to write the values overlay of region %d", cluster.RegionID)
+	valuesFiles = append(valuesFiles, overlayFile)
 
 	helmOptions := &helm.Options{
-		KubectlOptions: kubectlOptions,
+		KubectlOptions: &cluster.KubectlNamespace,
 		Version:        remoteChartVersion,
 		ValuesFiles:    valuesFiles,
 		SetValues:      setValues,
 		SetStrValues:   setStringValues,
 	}
 
-	if !strings.Contains(remoteChartVersion, "snapshot") {
+	if capabilities.Published {
 		helm.AddRepo(t, helmOptions, "camunda", remoteChartSource)
 	}
 
@@ -494,27 +599,37 @@ func InstallUpgradeC8Helm(t *testing.T, kubectlOptions *k8s.KubectlOptions, remo
 	helmOptions.ExtraArgs = map[string][]string{
 		"upgrade": {"--version", remoteChartVersion, "--install"},
 	}
-	helm.Upgrade(t, helmOptions, remoteChartName, "camunda")
+	return C8HelmRelease{Cluster: cluster, ChartName: remoteChartName, Options: helmOptions}
+}
 
-	// Write the old file back to the file - mostly for local development
-	err = os.WriteFile(filePath, []byte(fileContent), 0644)
-	if err != nil {
-		t.Fatalf("[C8 HELM] Error writing file: %v\n", err)
-		return
-	}
+// UpgradeE runs the helm upgrade, it only logs through t and can run in its own goroutine
+func (r C8HelmRelease) UpgradeE(t *testing.T) error {
+	return helm.UpgradeE(t, r.Options, r.ChartName, "camunda")
 }
-func extractReplacementText(output, variableName string) string {
-	startMarker := fmt.Sprintf("- name: %s\n  value: ", variableName)
-	startIndex := strings.Index(output, startMarker)
-	if startIndex == -1 {
-		return ""
-	}
-	startIndex += len(startMarker)
-	endIndex := strings.Index(output[startIndex:], "\n")
-	if endIndex == -1 {
-		return output[startIndex:]
+
+// UpgradeC8HelmConcurrently upgrades the releases of all regions in parallel, the brokers of a region only become ready once the other regions are up too
+func UpgradeC8HelmConcurrently(t *testing.T, releases []C8HelmRelease) {
+	t.Helper()
+	require.NoError(t, UpgradeC8HelmConcurrentlyE(t, releases))
+}
+
+// UpgradeC8HelmConcurrentlyE is UpgradeC8HelmConcurrently returning the failures of all regions instead of failing the test
+func UpgradeC8HelmConcurrentlyE(t *testing.T, releases []C8HelmRelease) error {
+	errs := make([]error, len(releases))
+	var wg sync.WaitGroup
+	for i, release := range releases {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t.Logf("[C8 HELM] Upgrading %s", release.Cluster.ClusterName)
+			if err := release.UpgradeE(t); err != nil {
+				errs[i] = fmt.Errorf("[C8 HELM] Failed to upgrade %s: %w", release.Cluster.ClusterName, err)
+			}
+		}()
 	}
-	return output[startIndex : startIndex+endIndex]
+	wg.Wait()
+
+	return errors.Join(errs...)
 }
 
 func StatefulSetContains(t *testing.T, kubectlOptions *k8s.KubectlOptions, statefulset, searchValue string) bool {
@@ -539,6 +654,83 @@ func StatefulSetContains(t *testing.T, kubectlOptions *k8s.KubectlOptions, state
 	return val
 }
 
+// GetStatefulSetReplicasE returns the desired replicas of the StatefulSet
+// A StatefulSet that does not exist is reported as ErrStatefulSetNotFound
+func GetStatefulSetReplicasE(t *testing.T, kubectlOptions *k8s.KubectlOptions, statefulset string) (int, error) {
+	output, err := k8s.RunKubectlAndGetOutputE(t, kubectlOptions, "get", "statefulset", statefulset, "--ignore-not-found", "-o", "jsonpath={.spec.replicas}")
+	if err != nil {
+		return 0, err
+	}
+
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return 0, ErrStatefulSetNotFound
+	}
+
+	replicas, err := strconv.Atoi(output)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected replicas %q for statefulset %s: %w", output, statefulset, err)
+	}
+	return replicas, nil
+}
+
+// GetStatefulSetReplicas returns the desired replicas of the StatefulSet and fails if it does not exist
+func GetStatefulSetReplicas(t *testing.T, kubectlOptions *k8s.KubectlOptions, statefulset string) int {
+	replicas, err := GetStatefulSetReplicasE(t, kubectlOptions, statefulset)
+	require.NoError(t, err, "[STATEFULSET] Failed to get replicas of %s", statefulset)
+	return replicas
+}
+
+// OrphanedStatefulSetPVCs returns the PVCs created by the volume claim templates of the StatefulSet for pods at or above replicas
+// PVC names follow the <template>-<statefulset>-<ordinal> convention and are kept by Kubernetes when a StatefulSet shrinks
+func OrphanedStatefulSetPVCs(pvcNames []string, statefulset string, replicas int) []string {
+	pattern := regexp.MustCompile(fmt.Sprintf(`^[a-z0-9-]+-%s-([0-9]+)$`, regexp.QuoteMeta(statefulset)))
+
+	var orphaned []string
+	for _, name := range pvcNames {
+		match := pattern.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+		if ordinal, err := strconv.Atoi(match[1]); err == nil && ordinal >= replicas {
+			orphaned = append(orphaned, name)
+		}
+	}
+	return orphaned
+}
+
+// DeleteOrphanedStatefulSetPVCs deletes the PVCs of the StatefulSet that are no longer used by any of its replicas
+// Must only be called once the pods above replicas are gone, as Kubernetes keeps a PVC in use until its pod is deleted
+func DeleteOrphanedStatefulSetPVCs(t *testing.T, kubectlOptions *k8s.KubectlOptions, statefulset string, replicas int) []string {
+	var names []string
+	for _, pvc := range k8s.ListPersistentVolumeClaims(t, kubectlOptions, metav1.ListOptions{}) {
+		names = append(names, pvc.Name)
+	}
+
+	orphaned := OrphanedStatefulSetPVCs(names, statefulset, replicas)
+	for _, name := range orphaned {
+		t.Logf("[STATEFULSET] Deleting orphaned PVC %s/%s", kubectlOptions.Namespace, name)
+		k8s.RunKubectl(t, kubectlOptions, "delete", "pvc", name)
+	}
+	return orphaned
+}
+
+// WaitForPodDeleted waits until the pod no longer exists, e.g. after shrinking its StatefulSet
+func WaitForPodDeleted(t *testing.T, kubectlOptions *k8s.KubectlOptions, podName string, maxRetries int, interval time.Duration) {
+	for i := 0; i < maxRetries; i++ {
+		pod, err := k8s.GetPodE(t, kubectlOptions, podName)
+		if err != nil && k8serrors.IsNotFound(err) {
+			t.Logf("[STATEFULSET] Pod %s/%s is gone", kubectlOptions.Namespace, podName)
+			return
+		}
+		require.NoError(t, err, "[STATEFULSET] Failed to get pod %s/%s", kubectlOptions.Namespace, podName)
+
+		t.Logf("[STATEFULSET] Pod %s/%s still exists (phase: %s), waiting... (attempt %d/%d)", kubectlOptions.Namespace, podName, pod.Status.Phase, i+1, maxRetries)
+		time.Sleep(interval)
+	}
+	t.Fatalf("[STATEFULSET] Pod %s/%s was not deleted", kubectlOptions.Namespace, podName)
+}
+
 func GetZeebeBrokerId(t *testing.T, kubectlOptions *k8s.KubectlOptions, podName string) int {
 	t.Logf("[ZEEBE BROKER ID] Getting Zeebe Broker ID for pod %s", podName)
 
@@ -559,29 +751,30 @@ func GetZeebeBrokerId(t *testing.T, kubectlOptions *k8s.KubectlOptions, podName
 		return -1
 	}
 
-	return helpers.CutOutString(output, "ORCHESTRATION_NODE_ID=[0-9]")
+	return helpers.CutOutString(output, "ORCHESTRATION_NODE_ID=([0-9]+)")
 }
 
-func CheckC8RunningProperly(t *testing.T, primary helpers.Cluster, namespace0, namespace1 string) {
-	topology := GetClusterTopology(t, &primary.KubectlNamespace)
+// CheckC8RunningProperly verifies the gateway of the given cluster sees clusterSize brokers, spread evenly across the namespaces
+func CheckC8RunningProperly(t *testing.T, gateway helpers.Cluster, namespaces []string, clusterSize int) {
+	topology := GetClusterTopology(t, &gateway.KubectlNamespace)
 
-	require.Equal(t, 8, len(topology.Brokers))
+	require.Equal(t, clusterSize, len(topology.Brokers))
 
-	primaryCount := 0
-	secondaryCount := 0
+	counts := make([]int, len(namespaces))
 
 	t.Log("[C8 CHECK] Cluster status:")
 	for _, broker := range topology.Brokers {
-		if strings.Contains(broker.Host, namespace0) {
-			primaryCount++
-		} else if strings.Contains(broker.Host, namespace1) {
-			secondaryCount++
+		for region, namespace := range namespaces {
+			if strings.Contains(broker.Host, "."+namespace+".") {
+				counts[region]++
+			}
 		}
 		t.Logf("[C8 CHECK] Broker ID: %d, Address: %s, Partitions: %v\n", broker.NodeId, broker.Host, broker.Partitions)
 	}
 
-	require.Equal(t, 4, primaryCount)
-	require.Equal(t, 4, secondaryCount)
+	for region, count := range counts {
+		require.Equal(t, clusterSize/len(namespaces), count, "Unexpected number of brokers in region %d", region)
+	}
 }
 
 func DeployC8processAndCheck(t *testing.T, kubectlOptions helpers.Cluster, resourceDir, tenantId string) {
@@ -964,14 +1157,28 @@ func CheckElasticsearchClusterHealth(t *testing.T, cluster helpers.Cluster) {
 func GetClusterTopology(t *testing.T, kubectlOptions *k8s.KubectlOptions) ClusterInfo {
 	t.Helper()
 
-	service := k8s.GetService(t, kubectlOptions, "camunda-zeebe-gateway")
-	require.Equal(t, "camunda-zeebe-gateway", service.Name)
+	clusterInfo, err := GetClusterTopologyE(t, kubectlOptions)
+	require.NoError(t, err, "[CLUSTER TOPOLOGY] Failed to get topology")
+
+	return clusterInfo
+}
+
+// GetClusterTopologyE queries v2/topology through the gateway of the namespace, returning an error instead of failing the test
+func GetClusterTopologyE(t *testing.T, kubectlOptions *k8s.KubectlOptions) (ClusterInfo, error) {
+	t.Helper()
+
+	if _, err := k8s.GetServiceE(t, kubectlOptions, "camunda-zeebe-gateway"); err != nil {
+		return ClusterInfo{}, err
+	}
 
-	endpoint, closeFn := NewServiceTunnelWithRetry(t, kubectlOptions, "camunda-zeebe-gateway", 0, 8080, 5, 15*time.Second)
+	endpoint, closeFn, err := newTunnelWithRetryE(t, kubectlOptions, k8s.ResourceTypeService, "camunda-zeebe-gateway", 0, 8080, 5, 15*time.Second)
+	if err != nil {
+		return ClusterInfo{}, err
+	}
 	defer closeFn()
 
 	// Get topology from v2/topology endpoint
-	code, body := http_helper.HTTPDoWithOptions(t, http_helper.HttpDoOptions{
+	code, body, err := http_helper.HTTPDoWithOptionsE(t, http_helper.HttpDoOptions{
 		Method: "GET",
 		Url:    fmt.Sprintf("http://%s/v2/topology", endpoint),
 		Headers: map[string]string{
@@ -981,16 +1188,18 @@ func GetClusterTopology(t *testing.T, kubectlOptions *k8s.KubectlOptions) Cluste
 		TlsConfig: nil,
 		Timeout:   30,
 	})
+	if err != nil {
+		return ClusterInfo{}, err
+	}
 	if code != 200 {
-		t.Fatalf("[CLUSTER TOPOLOGY] Failed to get topology (status %d): %s", code, body)
-		return ClusterInfo{}
+		return ClusterInfo{}, fmt.Errorf("unexpected status %d: %s", code, body)
 	}
-	require.NotEmpty(t, body)
 
 	// Parse the topology response
 	var clusterInfo ClusterInfo
-	err := json.Unmarshal([]byte(body), &clusterInfo)
-	require.NoError(t, err, "Failed to parse topology response")
+	if err := json.Unmarshal([]byte(body), &clusterInfo); err != nil {
+		return ClusterInfo{}, fmt.Errorf("failed to parse topology response: %w", err)
+	}
 
-	return clusterInfo
+	return clusterInfo, nil
 }
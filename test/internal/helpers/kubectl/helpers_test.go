@@ -0,0 +1,21 @@
+package kubectlHelpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrphanedStatefulSetPVCs(t *testing.T) {
+	pvcs := []string{
+		"data-camunda-zeebe-0",
+		"data-camunda-zeebe-3",
+		"data-camunda-zeebe-4",
+		"data-camunda-zeebe-11",
+		"data-camunda-zeebe-gateway-4",
+		"data-camunda-elasticsearch-master-5",
+	}
+
+	require.Equal(t, []string{"data-camunda-zeebe-4", "data-camunda-zeebe-11"}, OrphanedStatefulSetPVCs(pvcs, "camunda-zeebe", 4))
+	require.Empty(t, OrphanedStatefulSetPVCs(pvcs, "camunda-zeebe", 12))
+}
@@ -0,0 +1,135 @@
+package kubectlHelpers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"multiregiontests/internal/helpers"
+
+	"github.com/gruntwork-io/terratest/modules/helm"
+	"github.com/gruntwork-io/terratest/modules/logger"
+)
+
+// releaseHistory is an entry of helm history -o json
+type releaseHistory struct {
+	Revision int    `json:"revision"`
+	Status   string `json:"status"`
+}
+
+// DeployedRevision returns the revision of the history Helm currently considers deployed, 0 if there is none
+func DeployedRevision(historyJSON []byte) (int, error) {
+	var history []releaseHistory
+	if err := json.Unmarshal(historyJSON, &history); err != nil {
+		return 0, fmt.Errorf("failed to parse the release history: %w", err)
+	}
+
+	revision := 0
+	for _, entry := range history {
+		if entry.Status == "deployed" && entry.Revision > revision {
+			revision = entry.Revision
+		}
+	}
+	return revision, nil
+}
+
+// ReleaseRevisionsE records the deployed revision of the camunda release of every region, 0 for regions without a release yet
+func ReleaseRevisionsE(t *testing.T, clusters helpers.Clusters) ([]int, error) {
+	revisions := make([]int, len(clusters))
+	for region := range clusters {
+		options := &helm.Options{KubectlOptions: &clusters[region].KubectlNamespace, Logger: logger.Discard}
+		output, err := helm.RunHelmCommandAndGetStdOutE(t, options, "history", "camunda", "--output", "json")
+		if err != nil {
+			if strings.Contains(err.Error(), "release: not found") {
+				continue
+			}
+			return nil, fmt.Errorf("[C8 HELM] Failed to read the release history of region %d: %w", region, err)
+		}
+
+		revisions[region], err = DeployedRevision([]byte(output))
+		if err != nil {
+			return nil, fmt.Errorf("[C8 HELM] Region %d: %w", region, err)
+		}
+	}
+	return revisions, nil
+}
+
+// RollbackReleasesE rolls the camunda release of every region back to its recorded revision in parallel
+// Regions without a recorded revision had no release before and are left as they are
+func RollbackReleasesE(t *testing.T, clusters helpers.Clusters, revisions []int) error {
+	errs := make([]error, len(clusters))
+	var wg sync.WaitGroup
+	for region := range clusters {
+		if revisions[region] == 0 {
+			t.Logf("[C8 HELM] Region %d had no release before the upgrade, nothing to roll back", region)
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t.Logf("[C8 HELM] Rolling back region %d to revision %d", region, revisions[region])
+			options := &helm.Options{KubectlOptions: &clusters[region].KubectlNamespace}
+			if err := helm.RollbackE(t, options, "camunda", strconv.Itoa(revisions[region])); err != nil {
+				errs[region] = fmt.Errorf("[C8 HELM] Failed to roll back region %d: %w", region, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// UpgradeC8HelmAtomically upgrades the releases of all regions together and waits for the workloads to roll out
+// If any region fails, every region is rolled back to the revision it had before, so the regions never run mixed releases
+// Once the previous revision rolled out again, verify checks the regions are healthy, if set and all regions had a previous release, the test fails either way
+func UpgradeC8HelmAtomically(t *testing.T, releases []C8HelmRelease, workloads []string, timeout, interval time.Duration, verify func(t *testing.T)) {
+	t.Helper()
+
+	clusters := make(helpers.Clusters, len(releases))
+	for i := range releases {
+		clusters[i] = releases[i].Cluster
+	}
+
+	revisions, err := ReleaseRevisionsE(t, clusters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("[C8 HELM] Revisions before the upgrade: %v", revisions)
+
+	err = UpgradeC8HelmConcurrentlyE(t, releases)
+	if err == nil {
+		err = WatchRolloutsE(t, clusters, workloads, timeout, interval)
+	}
+	if err == nil {
+		return
+	}
+
+	t.Logf("[C8 HELM] Upgrade failed, rolling back all regions: %v", err)
+	if rollbackErr := RollbackReleasesE(t, clusters, revisions); rollbackErr != nil {
+		t.Fatalf("%v\n%v", err, rollbackErr)
+	}
+
+	// Regions installed fresh keep their failed release, the topology can't be healthy then
+	for _, revision := range revisions {
+		if revision == 0 {
+			t.Fatal(err)
+		}
+	}
+
+	// Pods of the failed revision keep crash looping until they are replaced, so only the finished rollback counts
+	if rolloutErr := WaitForRolloutsE(t, clusters, workloads, timeout, interval); rolloutErr != nil {
+		t.Fatalf("%v\n[C8 HELM] Rollback did not recover: %v", err, rolloutErr)
+	}
+	if verify != nil {
+		verify(t)
+	}
+	t.Logf("[C8 HELM] All regions are back on their previous revision %v", revisions)
+
+	t.Fatal(err)
+}
@@ -0,0 +1,28 @@
+package kubectlHelpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeployedRevision(t *testing.T) {
+	revision, err := DeployedRevision([]byte(`[
+		{"revision":1,"status":"superseded","chart":"camunda-platform-13.0.0"},
+		{"revision":2,"status":"deployed","chart":"camunda-platform-13.0.1"},
+		{"revision":3,"status":"failed","chart":"camunda-platform-13.1.0"}
+	]`))
+	require.NoError(t, err)
+	require.Equal(t, 2, revision)
+
+	revision, err = DeployedRevision([]byte(`[{"revision":1,"status":"failed"}]`))
+	require.NoError(t, err)
+	require.Zero(t, revision)
+
+	revision, err = DeployedRevision([]byte(`[]`))
+	require.NoError(t, err)
+	require.Zero(t, revision)
+
+	_, err = DeployedRevision([]byte(`Error: release: not found`))
+	require.Error(t, err)
+}
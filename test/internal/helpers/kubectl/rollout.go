@@ -0,0 +1,261 @@
+package kubectlHelpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"multiregiontests/internal/helpers"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// failingReasons are container states that don't resolve by waiting longer
+var failingReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+// crashLoopRestarts is the number of restarts after which a crash looping container counts as failing
+// Elasticsearch and Zeebe restart a few times during startup until their peers are reachable
+const crashLoopRestarts = 5
+
+// WorkloadStatus is the rollout progress of a StatefulSet or Deployment
+type WorkloadStatus struct {
+	Workload string
+	Ready    int
+	Updated  int
+	// Replicas counts the pods of all revisions, old pods are still terminating while it exceeds Desired
+	Replicas int
+	Desired  int
+	// Generation and ObservedGeneration differ until the controller picked up the latest spec
+	Generation         int64
+	ObservedGeneration int64
+	// CurrentRevision and UpdateRevision differ until a StatefulSet replaced all pods, empty for Deployments
+	CurrentRevision string
+	UpdateRevision  string
+	// Selector matches the pods of the workload
+	Selector string
+	// Err is set if the status could not be read, e.g. because the workload does not exist yet
+	Err error
+}
+
+// Done reports whether the controller rolled out the latest spec and every replica of it is ready, like kubectl rollout status
+// Ready replicas alone don't tell, a running release has them all before the upgrade replaced a single pod
+func (s WorkloadStatus) Done() bool {
+	return s.Err == nil && s.Desired > 0 &&
+		s.ObservedGeneration >= s.Generation &&
+		s.Updated >= s.Desired && s.Replicas <= s.Desired && s.Ready >= s.Desired &&
+		s.CurrentRevision == s.UpdateRevision
+}
+
+func (s WorkloadStatus) String() string {
+	name := s.Workload[strings.Index(s.Workload, "/")+1:]
+	switch {
+	case s.Err != nil:
+		return fmt.Sprintf("%s ?", name)
+	case s.Done():
+		return fmt.Sprintf("%s %d/%d ✅", name, s.Ready, s.Desired)
+	}
+	return fmt.Sprintf("%s %d/%d", name, s.Ready, s.Desired)
+}
+
+// RegionRollout is the state of all watched workloads of a region
+type RegionRollout struct {
+	Region    int
+	Workloads []WorkloadStatus
+	// Failing lists pods that won't become ready without intervention
+	Failing []string
+}
+
+// Done reports whether all workloads of the region are ready
+func (r RegionRollout) Done() bool {
+	for _, workload := range r.Workloads {
+		if !workload.Done() {
+			return false
+		}
+	}
+	return true
+}
+
+// RolloutProgress renders the combined progress of all regions on one line
+func RolloutProgress(rollouts []RegionRollout) string {
+	regions := make([]string, len(rollouts))
+	for i, rollout := range rollouts {
+		workloads := make([]string, len(rollout.Workloads))
+		for j, workload := range rollout.Workloads {
+			workloads[j] = workload.String()
+		}
+		regions[i] = fmt.Sprintf("region %d: %s", rollout.Region, strings.Join(workloads, ", "))
+	}
+	return strings.Join(regions, " | ")
+}
+
+// FailingPodReason returns why a pod won't become ready on its own, empty if it is fine or still starting
+func FailingPodReason(pod corev1.Pod) string {
+	if pod.Status.Phase == corev1.PodFailed {
+		return fmt.Sprintf("pod failed: %s", pod.Status.Reason)
+	}
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		waiting := status.State.Waiting
+		if waiting == nil || !failingReasons[waiting.Reason] {
+			continue
+		}
+		if waiting.Reason == "CrashLoopBackOff" && status.RestartCount < crashLoopRestarts {
+			continue
+		}
+		return fmt.Sprintf("container %s: %s %s", status.Name, waiting.Reason, waiting.Message)
+	}
+	return ""
+}
+
+// WatchRollouts waits until the workloads, like statefulset/camunda-zeebe, are rolled out in every region
+// All regions are polled at once and their combined progress logged, the watch fails as soon as a pod of a watched workload is failing
+func WatchRollouts(t *testing.T, clusters helpers.Clusters, workloads []string, timeout, interval time.Duration) {
+	t.Helper()
+	require.NoError(t, WatchRolloutsE(t, clusters, workloads, timeout, interval))
+}
+
+// WatchRolloutsE is WatchRollouts returning the failure instead of failing the test
+func WatchRolloutsE(t *testing.T, clusters helpers.Clusters, workloads []string, timeout, interval time.Duration) error {
+	t.Helper()
+	return watchRollouts(t, clusters, workloads, timeout, interval, true)
+}
+
+// WaitForRolloutsE waits until the workloads are rolled out in every region without failing fast
+// Pods of the previous revision may still be failing while they are replaced, e.g. after a rollback, failing pods only count once the rollout is done
+func WaitForRolloutsE(t *testing.T, clusters helpers.Clusters, workloads []string, timeout, interval time.Duration) error {
+	t.Helper()
+	return watchRollouts(t, clusters, workloads, timeout, interval, false)
+}
+
+func watchRollouts(t *testing.T, clusters helpers.Clusters, workloads []string, timeout, interval time.Duration, failFast bool) error {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		rollouts := pollRollouts(t, clusters, workloads)
+		t.Logf("[ROLLOUT] %s", RolloutProgress(rollouts))
+
+		done := true
+		for _, rollout := range rollouts {
+			done = done && rollout.Done()
+		}
+		for _, rollout := range rollouts {
+			if len(rollout.Failing) > 0 && (failFast || done) {
+				return fmt.Errorf("[ROLLOUT] Region %d is failing: %s", rollout.Region, strings.Join(rollout.Failing, "; "))
+			}
+		}
+		if done {
+			t.Log("[ROLLOUT] All regions are ready")
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("[ROLLOUT] Regions not ready after %s: %s", timeout, RolloutProgress(rollouts))
+		}
+		time.Sleep(interval)
+	}
+}
+
+// pollRollouts reads the state of all regions in parallel, only through E variants as it runs outside the test goroutine
+func pollRollouts(t *testing.T, clusters helpers.Clusters, workloads []string) []RegionRollout {
+	rollouts := make([]RegionRollout, len(clusters))
+	var wg sync.WaitGroup
+	for region := range clusters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Copy the options, the logger is silenced for the polls only
+			options := clusters[region].KubectlNamespace
+			options.Logger = logger.Discard
+
+			rollout := RegionRollout{Region: clusters[region].RegionID}
+			for _, workload := range workloads {
+				status := workloadStatus(t, &options, workload)
+				rollout.Workloads = append(rollout.Workloads, status)
+
+				// Only pods of the watched workloads count, other pods of the namespace may restart at will
+				if status.Selector == "" {
+					continue
+				}
+				if pods, err := k8s.ListPodsE(t, &options, metav1.ListOptions{LabelSelector: status.Selector}); err == nil {
+					for _, pod := range pods {
+						if reason := FailingPodReason(pod); reason != "" {
+							rollout.Failing = append(rollout.Failing, fmt.Sprintf("%s %s", pod.Name, reason))
+						}
+					}
+				}
+			}
+			rollouts[region] = rollout
+		}()
+	}
+	wg.Wait()
+	return rollouts
+}
+
+func workloadStatus(t *testing.T, options *k8s.KubectlOptions, workload string) WorkloadStatus {
+	output, err := k8s.RunKubectlAndGetOutputE(t, options, "get", workload, "-o", "json")
+	if err != nil {
+		return WorkloadStatus{Workload: workload, Err: err}
+	}
+	return ParseWorkloadStatus(workload, []byte(output))
+}
+
+// ParseWorkloadStatus reads the rollout progress from the JSON of a StatefulSet or Deployment
+func ParseWorkloadStatus(workload string, object []byte) WorkloadStatus {
+	status := WorkloadStatus{Workload: workload}
+
+	var parsed struct {
+		Metadata struct {
+			Generation int64 `json:"generation"`
+		} `json:"metadata"`
+		Spec struct {
+			Replicas *int `json:"replicas"`
+			Selector struct {
+				MatchLabels map[string]string `json:"matchLabels"`
+			} `json:"selector"`
+		} `json:"spec"`
+		Status struct {
+			ObservedGeneration int64  `json:"observedGeneration"`
+			Replicas           int    `json:"replicas"`
+			ReadyReplicas      int    `json:"readyReplicas"`
+			UpdatedReplicas    int    `json:"updatedReplicas"`
+			CurrentRevision    string `json:"currentRevision"`
+			UpdateRevision     string `json:"updateRevision"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(object, &parsed); err != nil {
+		status.Err = err
+		return status
+	}
+
+	// Both default to a single replica
+	status.Desired = 1
+	if parsed.Spec.Replicas != nil {
+		status.Desired = *parsed.Spec.Replicas
+	}
+	status.Ready, status.Updated, status.Replicas = parsed.Status.ReadyReplicas, parsed.Status.UpdatedReplicas, parsed.Status.Replicas
+	status.Generation, status.ObservedGeneration = parsed.Metadata.Generation, parsed.Status.ObservedGeneration
+	status.CurrentRevision, status.UpdateRevision = parsed.Status.CurrentRevision, parsed.Status.UpdateRevision
+
+	labels := make([]string, 0, len(parsed.Spec.Selector.MatchLabels))
+	for key, value := range parsed.Spec.Selector.MatchLabels {
+		labels = append(labels, key+"="+value)
+	}
+	sort.Strings(labels)
+	status.Selector = strings.Join(labels, ",")
+	return status
+}
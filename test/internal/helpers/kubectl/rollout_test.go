@@ -0,0 +1,90 @@
+package kubectlHelpers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestFailingPodReason(t *testing.T) {
+	starting := corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+		{Name: "zeebe", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+	}}}
+	require.Empty(t, FailingPodReason(starting))
+
+	// Brokers restart a few times until the other regions are reachable
+	restarting := corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+		{Name: "zeebe", RestartCount: 2, State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 20s"}}},
+	}}}
+	require.Empty(t, FailingPodReason(restarting))
+
+	crashing := corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+		{Name: "zeebe", RestartCount: crashLoopRestarts, State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 5m0s"}}},
+	}}}
+	require.Equal(t, "container zeebe: CrashLoopBackOff back-off 5m0s", FailingPodReason(crashing))
+
+	initPull := corev1.Pod{Status: corev1.PodStatus{InitContainerStatuses: []corev1.ContainerStatus{
+		{Name: "init", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+	}}}
+	require.Contains(t, FailingPodReason(initPull), "init: ImagePullBackOff")
+
+	failed := corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"}}
+	require.Equal(t, "pod failed: Evicted", FailingPodReason(failed))
+}
+
+func TestRolloutProgress(t *testing.T) {
+	rollouts := []RegionRollout{
+		{Region: 0, Workloads: []WorkloadStatus{
+			{Workload: "statefulset/camunda-zeebe", Ready: 4, Updated: 4, Replicas: 4, Desired: 4},
+			{Workload: "deployment/camunda-connectors", Ready: 0, Updated: 1, Replicas: 1, Desired: 1},
+		}},
+		{Region: 1, Workloads: []WorkloadStatus{
+			{Workload: "statefulset/camunda-zeebe", Err: errors.New("not found")},
+		}},
+	}
+
+	require.Equal(t, "region 0: camunda-zeebe 4/4 ✅, camunda-connectors 0/1 | region 1: camunda-zeebe ?", RolloutProgress(rollouts))
+	require.False(t, rollouts[0].Done())
+	require.False(t, rollouts[1].Done())
+
+	rollouts[0].Workloads[1].Ready = 1
+	require.True(t, rollouts[0].Done())
+
+	// A workload scaled to zero has not rolled out anything
+	require.False(t, WorkloadStatus{Workload: "statefulset/camunda-zeebe"}.Done())
+}
+
+func TestParseWorkloadStatus(t *testing.T) {
+	// helm upgrade of a running release, the controller did not pick up the new spec yet
+	upgraded := ParseWorkloadStatus("statefulset/camunda-zeebe", []byte(`{
+		"metadata": {"generation": 3},
+		"spec": {"replicas": 4, "selector": {"matchLabels": {"app.kubernetes.io/name": "camunda-platform", "app.kubernetes.io/component": "zeebe-broker"}}},
+		"status": {"observedGeneration": 2, "replicas": 4, "readyReplicas": 4, "updatedReplicas": 4, "currentRevision": "camunda-zeebe-1", "updateRevision": "camunda-zeebe-1"}
+	}`))
+	require.NoError(t, upgraded.Err)
+	require.Equal(t, "app.kubernetes.io/component=zeebe-broker,app.kubernetes.io/name=camunda-platform", upgraded.Selector)
+	require.False(t, upgraded.Done(), "all replicas are ready, but of the previous spec")
+
+	// The controller picked it up, but no pod was replaced yet
+	upgraded.ObservedGeneration, upgraded.Updated, upgraded.UpdateRevision = 3, 0, "camunda-zeebe-2"
+	require.False(t, upgraded.Done())
+
+	// All pods replaced, the StatefulSet only moves the current revision once the last one is ready
+	upgraded.Updated = 4
+	require.False(t, upgraded.Done())
+	upgraded.CurrentRevision = "camunda-zeebe-2"
+	require.True(t, upgraded.Done())
+
+	// A Deployment still terminating pods of the old ReplicaSet
+	deployment := ParseWorkloadStatus("deployment/camunda-connectors", []byte(`{
+		"metadata": {"generation": 2},
+		"spec": {"selector": {"matchLabels": {"app": "connectors"}}},
+		"status": {"observedGeneration": 2, "replicas": 2, "readyReplicas": 1, "updatedReplicas": 1}
+	}`))
+	require.Equal(t, 1, deployment.Desired)
+	require.False(t, deployment.Done())
+	deployment.Replicas = 1
+	require.True(t, deployment.Done())
+}
@@ -0,0 +1,25 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// RenderOverlay writes the rendered content of a source manifest or values file into a temporary file of the test and returns its path
+// The source stays untouched, the file is removed together with the temporary directory of the test
+func RenderOverlay(t *testing.T, source, content string) (string, error) {
+	t.Helper()
+
+	file, err := os.CreateTemp(t.TempDir(), "*-"+filepath.Base(source))
+	if err != nil {
+		return "", fmt.Errorf("failed to create overlay of %s: %w", source, err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(content); err != nil {
+		return "", fmt.Errorf("failed to write overlay of %s: %w", source, err)
+	}
+	return file.Name(), nil
+}
@@ -0,0 +1,28 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderOverlayKeepsSource(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "camunda-values.yml")
+	require.NoError(t, os.WriteFile(source, []byte("value: PLACEHOLDER\n"), 0644))
+
+	overlay, err := RenderOverlay(t, source, "value: rendered\n")
+	require.NoError(t, err)
+	require.NotEqual(t, source, overlay)
+	require.True(t, strings.HasSuffix(overlay, "-camunda-values.yml"))
+
+	content, err := os.ReadFile(overlay)
+	require.NoError(t, err)
+	require.Equal(t, "value: rendered\n", string(content))
+
+	content, err = os.ReadFile(source)
+	require.NoError(t, err)
+	require.Equal(t, "value: PLACEHOLDER\n", string(content))
+}
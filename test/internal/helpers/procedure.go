@@ -0,0 +1,177 @@
+package helpers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Step is a single state of a Procedure
+// A crash between running the step and persisting the checkpoint reruns it, steps that can't simply be repeated need a Done check
+type Step struct {
+	Name string
+	// Always steps run on every attempt and are never checkpointed, e.g. setting up clients or read-only checks
+	// Only Always steps may share their name with another step
+	Always bool
+	// Done reports whether the effect of the step is already in place, the step is then checkpointed without running
+	Done func(t *testing.T) bool
+	// Precondition verifies the cluster is in the state the step expects, the step fails without running otherwise
+	Precondition func(t *testing.T) error
+	Run          func(t *testing.T)
+}
+
+// Checkpoints is the persisted progress of a Procedure
+type Checkpoints struct {
+	Procedure  string    `json:"procedure"`
+	Deployment string    `json:"deployment"`
+	Completed  []string  `json:"completed"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// Procedure runs its steps in order as subtests and persists a checkpoint after every completed step
+// A rerun skips all checkpointed steps and resumes with the first step that did not complete
+type Procedure struct {
+	Name string
+	// StateFile holds the checkpoints, without one every run starts from the beginning and nothing is persisted
+	StateFile string
+	// Deployment identifies what the procedure runs against, checkpoints of a different deployment are rejected
+	Deployment string
+	Steps      []Step
+}
+
+// DeploymentKey identifies a deployment by the namespaces of its regions and the chart version
+func DeploymentKey(namespaces []string, chartVersion string) string {
+	return strings.Join(namespaces, ",") + "@" + chartVersion
+}
+
+// NewProcedure creates a Procedure that persists its checkpoints to stateFile, if set, for the given deployment
+func NewProcedure(name, stateFile, deployment string, steps ...Step) *Procedure {
+	return &Procedure{
+		Name:       name,
+		StateFile:  stateFile,
+		Deployment: deployment,
+		Steps:      steps,
+	}
+}
+
+// LoadCheckpoints reads the checkpoints of the procedure, a missing state file means nothing completed yet
+func (p *Procedure) LoadCheckpoints() (Checkpoints, error) {
+	checkpoints := Checkpoints{Procedure: p.Name, Deployment: p.Deployment}
+	if p.StateFile == "" {
+		return checkpoints, nil
+	}
+
+	content, err := os.ReadFile(p.StateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoints, nil
+	}
+	if err != nil {
+		return checkpoints, err
+	}
+
+	if err := json.Unmarshal(content, &checkpoints); err != nil {
+		return checkpoints, fmt.Errorf("failed to parse state file %s: %w", p.StateFile, err)
+	}
+	if checkpoints.Procedure != p.Name {
+		return checkpoints, fmt.Errorf("state file %s belongs to procedure %q, not %q", p.StateFile, checkpoints.Procedure, p.Name)
+	}
+	if checkpoints.Deployment != p.Deployment {
+		return checkpoints, fmt.Errorf("state file %s belongs to deployment %q, not %q, remove it to start from the beginning", p.StateFile, checkpoints.Deployment, p.Deployment)
+	}
+	return checkpoints, nil
+}
+
+// SaveCheckpoints persists the checkpoints, writing to a temporary file first so an interrupted write keeps the previous state
+func (p *Procedure) SaveCheckpoints(checkpoints Checkpoints) error {
+	if p.StateFile == "" {
+		return nil
+	}
+	checkpoints.UpdatedAt = time.Now().UTC()
+
+	content, err := json.MarshalIndent(checkpoints, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p.StateFile), 0755); err != nil {
+		return err
+	}
+	tmp := p.StateFile + ".tmp"
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.StateFile)
+}
+
+// Run executes all steps that are not checkpointed yet and stops at the first failing one
+// The state file is removed once every step completed
+func (p *Procedure) Run(t *testing.T) {
+	t.Helper()
+
+	seen := map[string]bool{}
+	for _, step := range p.Steps {
+		if step.Always {
+			continue
+		}
+		if seen[step.Name] {
+			t.Fatalf("[PROCEDURE] %s has more than one step named %s", p.Name, step.Name)
+		}
+		seen[step.Name] = true
+	}
+
+	checkpoints, err := p.LoadCheckpoints()
+	if err != nil {
+		t.Fatalf("[PROCEDURE] %v", err)
+	}
+	completed := map[string]bool{}
+	for _, name := range checkpoints.Completed {
+		completed[name] = true
+	}
+	if len(completed) > 0 {
+		t.Logf("[PROCEDURE] Resuming %s from %s, %d steps already completed", p.Name, p.StateFile, len(completed))
+	}
+
+	for _, step := range p.Steps {
+		if !step.Always && completed[step.Name] {
+			t.Logf("[PROCEDURE] Skipping %s, completed in a previous run", step.Name)
+			continue
+		}
+
+		ok := t.Run(step.Name, func(t *testing.T) {
+			if step.Done != nil && step.Done(t) {
+				t.Logf("[PROCEDURE] %s is already in place, not running it again", step.Name)
+				return
+			}
+			if step.Precondition != nil {
+				if err := step.Precondition(t); err != nil {
+					t.Fatalf("[PROCEDURE] Precondition of %s not met: %v", step.Name, err)
+				}
+			}
+			step.Run(t)
+		})
+		if !ok {
+			t.Fatalf("[PROCEDURE] %s failed at %s, rerun to resume from there (state: %s)", p.Name, step.Name, p.StateFile)
+		}
+
+		if step.Always {
+			continue
+		}
+		checkpoints.Completed = append(checkpoints.Completed, step.Name)
+		if err := p.SaveCheckpoints(checkpoints); err != nil {
+			t.Fatalf("[PROCEDURE] Failed to persist checkpoint after %s: %v", step.Name, err)
+		}
+	}
+
+	if p.StateFile == "" {
+		return
+	}
+	t.Logf("[PROCEDURE] %s completed, removing %s", p.Name, p.StateFile)
+	if err := os.Remove(p.StateFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("[PROCEDURE] Failed to remove state file: %v", err)
+	}
+}
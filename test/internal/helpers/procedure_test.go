@@ -0,0 +1,70 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcedureResumesFromCheckpoint(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "failback.json")
+
+	var ran []string
+	step := func(name string) Step {
+		return Step{Name: name, Run: func(t *testing.T) { ran = append(ran, name) }}
+	}
+	procedure := NewProcedure("failback", stateFile, "ns-0,ns-1@13.0.0",
+		Step{Name: "init", Always: true, Run: func(t *testing.T) { ran = append(ran, "init") }},
+		step("redeploy"),
+		Step{Name: "pause", Done: func(t *testing.T) bool { return true }, Run: func(t *testing.T) { ran = append(ran, "pause") }},
+		Step{Name: "restore", Precondition: func(t *testing.T) error { return nil }, Run: func(t *testing.T) { ran = append(ran, "restore") }},
+		Step{Name: "init", Always: true, Run: func(t *testing.T) { ran = append(ran, "init") }},
+	)
+
+	require.NoError(t, procedure.SaveCheckpoints(Checkpoints{Procedure: "failback", Deployment: "ns-0,ns-1@13.0.0", Completed: []string{"redeploy"}}))
+	checkpoints, err := procedure.LoadCheckpoints()
+	require.NoError(t, err)
+	require.Equal(t, []string{"redeploy"}, checkpoints.Completed)
+
+	procedure.Run(t)
+
+	require.Equal(t, []string{"init", "restore", "init"}, ran)
+	_, err = os.Stat(stateFile)
+	require.True(t, os.IsNotExist(err), "Expected the state file to be removed once the procedure completed")
+}
+
+func TestProcedureRejectsForeignStateFile(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, NewProcedure("failover", stateFile, "").SaveCheckpoints(Checkpoints{Procedure: "failover"}))
+
+	_, err := NewProcedure("failback", stateFile, "").LoadCheckpoints()
+	require.Error(t, err)
+}
+
+func TestProcedureRejectsStateOfOtherDeployment(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "failback.json")
+	require.NoError(t, NewProcedure("failback", stateFile, "ns-0,ns-1@12.0.0").SaveCheckpoints(Checkpoints{Procedure: "failback", Deployment: "ns-0,ns-1@12.0.0"}))
+
+	_, err := NewProcedure("failback", stateFile, "ns-0,ns-1@13.0.0").LoadCheckpoints()
+	require.ErrorContains(t, err, "belongs to deployment")
+}
+
+func TestDeploymentKey(t *testing.T) {
+	require.Equal(t, "ns-0,ns-1@13.0.0", DeploymentKey([]string{"ns-0", "ns-1"}, "13.0.0"))
+	require.NotEqual(t, DeploymentKey([]string{"ns-0", "ns-1"}, "13.0.0"), DeploymentKey([]string{"other-0", "other-1"}, "13.0.0"))
+	require.NotEqual(t, DeploymentKey([]string{"ns-0", "ns-1"}, "13.0.0"), DeploymentKey([]string{"ns-0", "ns-1"}, "13.1.0"))
+}
+
+func TestProcedureWithoutStateFile(t *testing.T) {
+	var ran []string
+	procedure := NewProcedure("failback", "", "",
+		Step{Name: "redeploy", Run: func(t *testing.T) { ran = append(ran, "redeploy") }},
+	)
+
+	procedure.Run(t)
+	procedure.Run(t)
+
+	require.Equal(t, []string{"redeploy", "redeploy"}, ran)
+}
@@ -0,0 +1,66 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+)
+
+// Clusters holds the Cluster of every region, indexed by RegionID
+type Clusters []Cluster
+
+// Validate checks that every cluster sits at the index of its RegionID, which all region indexed helpers rely on
+func (c Clusters) Validate() error {
+	for i := range c {
+		if c[i].RegionID != i {
+			return fmt.Errorf("cluster %s has region id %d but is configured as region %d", c[i].ClusterName, c[i].RegionID, i)
+		}
+	}
+	return nil
+}
+
+// Namespaces returns the Camunda namespace of every region, indexed by RegionID
+func (c Clusters) Namespaces() []string {
+	namespaces := make([]string, len(c))
+	for i := range c {
+		namespaces[c[i].RegionID] = c[i].KubectlNamespace.Namespace
+	}
+	return namespaces
+}
+
+// KubectlNamespaces returns the kubectl options of the Camunda namespace of every region
+// The options point into the slice, so they stay valid when the clusters are initialized later
+func (c Clusters) KubectlNamespaces() []*k8s.KubectlOptions {
+	options := make([]*k8s.KubectlOptions, len(c))
+	for i := range c {
+		options[i] = &c[i].KubectlNamespace
+	}
+	return options
+}
+
+// RegionIds returns all region ids but the excluded ones
+func (c Clusters) RegionIds(except ...int) []int {
+	var ids []int
+	for i := range c {
+		excluded := false
+		for _, e := range except {
+			excluded = excluded || e == c[i].RegionID
+		}
+		if !excluded {
+			ids = append(ids, c[i].RegionID)
+		}
+	}
+	return ids
+}
+
+// CoreDNSForwardEntry returns the CoreDNS server block forwarding the namespace of a remote region to its internal load balancer
+func CoreDNSForwardEntry(namespace string, ips []string) string {
+	return fmt.Sprintf(`%s.svc.cluster.local:53 {
+    errors
+    cache 30
+    forward . %s {
+        force_tcp
+    }
+}`, namespace, strings.Join(ips, " "))
+}
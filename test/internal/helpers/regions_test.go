@@ -0,0 +1,24 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClustersRegionIds(t *testing.T) {
+	clusters := Clusters{{RegionID: 0}, {RegionID: 1}, {RegionID: 2}}
+	require.NoError(t, clusters.Validate())
+	require.Equal(t, []int{0, 1, 2}, clusters.RegionIds())
+	require.Equal(t, []int{0, 2}, clusters.RegionIds(1))
+
+	// The zero value must not pass for a second region
+	require.ErrorContains(t, make(Clusters, 2).Validate(), "configured as region 1")
+}
+
+func TestCoreDNSForwardEntry(t *testing.T) {
+	entry := CoreDNSForwardEntry("ns-1", []string{"10.0.0.1", "10.0.0.2"})
+	require.True(t, strings.HasPrefix(entry, "ns-1.svc.cluster.local:53 {\n"))
+	require.Contains(t, entry, "    forward . 10.0.0.1 10.0.0.2 {\n        force_tcp\n")
+}
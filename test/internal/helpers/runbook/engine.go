@@ -0,0 +1,330 @@
+package runbookHelpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"multiregiontests/internal/helpers"
+	kubectlHelpers "multiregiontests/internal/helpers/kubectl"
+	zeebeHelpers "multiregiontests/internal/helpers/zeebe"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/stretchr/testify/require"
+)
+
+// defaultPollInterval applies to waitFor steps and cluster changes without an interval
+const defaultPollInterval = 15 * time.Second
+
+// HelmUpgradeFunc installs or upgrades the Camunda release of the region, on top of the values the caller always applies
+// The upgrade has to finish before the deadline of ctx
+type HelmUpgradeFunc func(ctx context.Context, t *testing.T, region int, valuesFiles []string, setValues, setStringValues map[string]string)
+
+// Environment is everything a runbook needs to act on the clusters, region ids index Regions
+type Environment struct {
+	Regions []helpers.Cluster
+	// RegionOf attributes brokers to regions for force removal gates and placement assertions
+	RegionOf     zeebeHelpers.RegionResolver
+	HelmUpgrade  HelmUpgradeFunc
+	BackupBucket string
+	ChartVersion string
+	// DryRun logs every step without touching the clusters
+	DryRun bool
+}
+
+// Engine executes a runbook step by step against an Environment
+type Engine struct {
+	Runbook Runbook
+	Env     Environment
+}
+
+// NewEngine creates an Engine for the runbook
+func NewEngine(runbook Runbook, env Environment) *Engine {
+	return &Engine{
+		Runbook: runbook,
+		Env:     env,
+	}
+}
+
+// CheckEnvironment verifies every region referenced by the runbook exists in the environment
+func (e *Engine) CheckEnvironment() error {
+	if err := e.Runbook.Validate(); err != nil {
+		return err
+	}
+
+	for _, step := range e.Runbook.Steps {
+		if step.Region >= len(e.Env.Regions) {
+			return fmt.Errorf("step %q runs against region %d, but only %d regions are configured", step.Name, step.Region, len(e.Env.Regions))
+		}
+		if step.Actuator != nil && step.Actuator.LostRegion != nil {
+			lost := *step.Actuator.LostRegion
+			if lost < 0 || lost >= len(e.Env.Regions) || lost == step.Region {
+				return fmt.Errorf("step %q names region %d as lost, it has to be another configured region", step.Name, lost)
+			}
+		}
+		if step.HelmUpgrade != nil && e.Env.HelmUpgrade == nil && !e.Env.DryRun {
+			return fmt.Errorf("step %q upgrades Helm, but the environment has no HelmUpgrade", step.Name)
+		}
+	}
+	return nil
+}
+
+// Run executes every step as a subtest and stops at the first failing one
+func (e *Engine) Run(t *testing.T) {
+	t.Helper()
+
+	require.NoError(t, e.CheckEnvironment(), "[RUNBOOK] Runbook %s can't run in this environment", e.Runbook.Name)
+
+	mode := ""
+	if e.Env.DryRun {
+		mode = " (dry run)"
+	}
+	t.Logf("[RUNBOOK] Running %s%s: %s 🚀", e.Runbook.Name, mode, e.Runbook.Description)
+
+	for i, step := range e.Runbook.Steps {
+		ok := t.Run(step.Name, func(t *testing.T) {
+			t.Logf("[RUNBOOK] Step %d/%d in region %d: %s", i+1, len(e.Runbook.Steps), step.Region, step.Describe())
+			if e.Env.DryRun {
+				return
+			}
+			require.NoError(t, e.runStepWithTimeout(t, step))
+		})
+		if !ok {
+			t.Fatalf("[RUNBOOK] %s failed at step %s", e.Runbook.Name, step.Name)
+		}
+	}
+
+	t.Logf("[RUNBOOK] %s completed", e.Runbook.Name)
+}
+
+// runStepWithTimeout runs the step with the deadline of its timeout
+// Helm and kubectl calls can't be interrupted, a step finishing after its deadline fails anyway
+func (e *Engine) runStepWithTimeout(t *testing.T, step Step) error {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), step.EffectiveTimeout())
+	defer cancel()
+
+	e.runStep(ctx, t, step)
+	if ctx.Err() != nil {
+		return fmt.Errorf("[RUNBOOK] Step %s did not finish within its timeout of %s", step.Name, step.EffectiveTimeout())
+	}
+	return nil
+}
+
+func (e *Engine) runStep(ctx context.Context, t *testing.T, step Step) {
+	t.Helper()
+
+	switch step.Type() {
+	case StepTypeActuator:
+		e.runActuator(ctx, t, step)
+	case StepTypeHelmUpgrade:
+		e.Env.HelmUpgrade(ctx, t, step.Region, step.HelmUpgrade.ValuesFiles, step.HelmUpgrade.Set, step.HelmUpgrade.SetString)
+	case StepTypeKubectlApply:
+		k8s.RunKubectl(t, e.namespace(step.Region), "apply", "-f", step.KubectlApply.Manifest, fmt.Sprintf("--request-timeout=%s", remaining(ctx)))
+	case StepTypeWaitFor:
+		e.runWaitFor(ctx, t, step)
+	case StepTypeESSnapshot:
+		e.runESSnapshot(t, step)
+	case StepTypeAssertTopology:
+		e.runAssertTopology(t, step)
+	}
+}
+
+func (e *Engine) runActuator(ctx context.Context, t *testing.T, step Step) {
+	t.Helper()
+	actuator := step.Actuator
+
+	query := url.Values{}
+	for key, value := range actuator.Query {
+		query.Set(key, value)
+	}
+
+	client, closeFn := zeebeHelpers.NewActuatorTunnel(t, e.namespace(step.Region))
+	defer closeFn()
+
+	body := actuator.Body
+	if actuator.RemoveLostRegionBrokers {
+		topology, err := client.GetCluster()
+		require.NoError(t, err, "[RUNBOOK] Failed to query the cluster members")
+		brokers := LostRegionBrokers(topology.BrokerIds(), len(e.Env.Regions), *actuator.LostRegion)
+		require.NotEmpty(t, brokers, "[RUNBOOK] Region %d has no brokers left to remove", *actuator.LostRegion)
+		t.Logf("[RUNBOOK] Removing brokers %v of region %d", brokers, *actuator.LostRegion)
+		body = zeebeHelpers.ClusterChangeRequest{Brokers: &zeebeHelpers.BrokersChange{Remove: brokers}}
+	}
+
+	var response json.RawMessage
+	if actuator.Force {
+		var change zeebeHelpers.ClusterChangeRequest
+		require.NoError(t, remarshal(body, &change), "[RUNBOOK] Force request body is not a cluster change")
+		var brokers []int
+		if change.Brokers != nil {
+			brokers = change.Brokers.Remove
+		}
+		gate := zeebeHelpers.RequireSafeForceRemoval(t, e.namespace(step.Region), e.namespace(*actuator.LostRegion), *actuator.LostRegion, len(e.Env.Regions), brokers, e.Env.RegionOf)
+
+		dryRun, _ := strconv.ParseBool(query.Get("dryRun"))
+		planned, err := client.PatchCluster(change, zeebeHelpers.PatchOptions{DryRun: dryRun, Force: gate})
+		require.NoError(t, err, "[RUNBOOK] Forced %s %s failed", actuator.Method, actuator.Path)
+		response, err = json.Marshal(planned)
+		require.NoError(t, err)
+	} else {
+		err := client.Do(strings.ToUpper(actuator.Method), actuator.Path, query, body, &response)
+		require.NoError(t, err, "[RUNBOOK] %s %s failed", actuator.Method, actuator.Path)
+	}
+	t.Logf("[RUNBOOK] Response: %s", string(response))
+
+	if !actuator.WaitForChange {
+		return
+	}
+
+	var planned zeebeHelpers.PlannedChangeResponse
+	require.NoError(t, json.Unmarshal(response, &planned), "[RUNBOOK] Response does not describe a cluster change")
+	if planned.ChangeId == 0 {
+		t.Log("[RUNBOOK] No cluster change started, nothing to wait for")
+		return
+	}
+	zeebeHelpers.WaitForClusterChange(t, client, planned.ChangeId, step.Name, retries(remaining(ctx), defaultPollInterval), defaultPollInterval)
+}
+
+func (e *Engine) runWaitFor(ctx context.Context, t *testing.T, step Step) {
+	t.Helper()
+	wait := step.WaitFor
+
+	interval := wait.Interval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	maxRetries := retries(remaining(ctx), interval)
+
+	switch wait.Condition {
+	case ConditionRollout:
+		k8s.RunKubectl(t, e.namespace(step.Region), "rollout", "status", "--watch", fmt.Sprintf("--timeout=%s", remaining(ctx)), wait.Resource)
+	case ConditionNoPendingChange:
+		client, closeFn := zeebeHelpers.NewActuatorTunnel(t, e.namespace(step.Region))
+		defer closeFn()
+		e.poll(ctx, t, step, maxRetries, interval, func() (bool, string) {
+			topology, err := client.GetCluster()
+			if err != nil {
+				return false, err.Error()
+			}
+			if topology.HasPendingChange() {
+				return false, fmt.Sprintf("change %d is still pending", topology.PendingChange.Id)
+			}
+			return true, ""
+		})
+	case ConditionExportingPhase:
+		var brokers []*k8s.KubectlOptions
+		for region := range e.Env.Regions {
+			brokers = append(brokers, e.namespace(region))
+		}
+		exporting := zeebeHelpers.NewExportingControl(e.namespace(step.Region), brokers...)
+		exporting.MaxRetries = maxRetries
+		exporting.Interval = interval
+		exporting.WaitForPhase(t, wait.Value)
+	case ConditionExporterStatus:
+		client, closeFn := zeebeHelpers.NewActuatorTunnel(t, e.namespace(step.Region))
+		defer closeFn()
+		e.poll(ctx, t, step, maxRetries, interval, func() (bool, string) {
+			exporters, err := client.GetExporters()
+			if err != nil {
+				return false, err.Error()
+			}
+			status := zeebeHelpers.ExporterStatusOf(exporters, wait.Exporter)
+			return status == wait.Value, fmt.Sprintf("exporter %s is %q", wait.Exporter, status)
+		})
+	case ConditionESHealth:
+		kubectlHelpers.CheckElasticsearchClusterHealth(t, e.Env.Regions[step.Region])
+	}
+}
+
+func (e *Engine) runESSnapshot(t *testing.T, step Step) {
+	t.Helper()
+	cluster := e.Env.Regions[step.Region]
+
+	switch step.ESSnapshot.Action {
+	case SnapshotActionConfigure:
+		kubectlHelpers.ConfigureElasticBackup(t, cluster, e.Env.BackupBucket, e.Env.ChartVersion)
+	case SnapshotActionCreate:
+		kubectlHelpers.CreateElasticBackup(t, cluster, step.ESSnapshot.Name)
+		kubectlHelpers.CheckThatElasticBackupIsPresent(t, cluster, step.ESSnapshot.Name, e.Env.BackupBucket, e.Env.ChartVersion)
+	case SnapshotActionRestore:
+		kubectlHelpers.CheckThatElasticBackupIsPresent(t, cluster, step.ESSnapshot.Name, e.Env.BackupBucket, e.Env.ChartVersion)
+		kubectlHelpers.RestoreElasticBackup(t, cluster, step.ESSnapshot.Name)
+	}
+}
+
+func (e *Engine) runAssertTopology(t *testing.T, step Step) {
+	t.Helper()
+	expected := step.AssertTopology
+
+	topology := kubectlHelpers.GetClusterTopology(t, e.namespace(step.Region))
+	if expected.Brokers > 0 {
+		require.Len(t, topology.Brokers, expected.Brokers, "[RUNBOOK] Unexpected number of brokers")
+	}
+	if expected.Partitions > 0 {
+		require.Equal(t, expected.Partitions, topology.PartitionsCount, "[RUNBOOK] Unexpected number of partitions")
+	}
+	if expected.ReplicationFactor > 0 {
+		require.Equal(t, expected.ReplicationFactor, topology.ReplicationFactor, "[RUNBOOK] Unexpected replication factor")
+	}
+	if expected.LostRegion != nil {
+		for _, broker := range topology.Brokers {
+			region, ok := e.Env.RegionOf(broker)
+			require.False(t, ok && region == *expected.LostRegion, "[RUNBOOK] Broker %d of the lost region %d is still in the topology", broker.NodeId, region)
+		}
+	}
+	if expected.ReplicasInEachRegion {
+		report := zeebeHelpers.AnalyzePlacement(topology, len(e.Env.Regions), e.Env.RegionOf)
+		zeebeHelpers.RequireReplicasInEveryRegion(t, report)
+	}
+}
+
+// poll calls check until it reports true, failing the step with the last reason after maxRetries or the deadline
+func (e *Engine) poll(ctx context.Context, t *testing.T, step Step, maxRetries int, interval time.Duration, check func() (bool, string)) {
+	t.Helper()
+
+	var reason string
+	for i := 0; i < maxRetries && ctx.Err() == nil; i++ {
+		var done bool
+		done, reason = check()
+		if done {
+			return
+		}
+		t.Logf("[RUNBOOK] Waiting for %s: %s (attempt %d/%d)", step.WaitFor.Condition, reason, i+1, maxRetries)
+		time.Sleep(interval)
+	}
+	t.Fatalf("[RUNBOOK] %s did not hold within %s: %s", step.WaitFor.Condition, step.EffectiveTimeout(), reason)
+}
+
+func (e *Engine) namespace(region int) *k8s.KubectlOptions {
+	return &e.Env.Regions[region].KubectlNamespace
+}
+
+// remaining returns the time left until the deadline of the step, at least a second as kubectl treats 0 as no timeout
+func remaining(ctx context.Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return DefaultStepTimeout
+	}
+	return max(time.Second, time.Until(deadline).Round(time.Second))
+}
+
+// retries converts a timeout into the number of polls at the given interval, polling at least once
+func retries(timeout, interval time.Duration) int {
+	return max(1, int(timeout/interval))
+}
+
+// remarshal converts a decoded YAML value into a typed struct through its JSON representation
+func remarshal(in, out interface{}) error {
+	content, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, out)
+}
@@ -0,0 +1,312 @@
+package runbookHelpers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Step types, every step sets exactly one of the matching blocks
+const (
+	StepTypeActuator       = "actuator"
+	StepTypeHelmUpgrade    = "helmUpgrade"
+	StepTypeKubectlApply   = "kubectlApply"
+	StepTypeWaitFor        = "waitFor"
+	StepTypeESSnapshot     = "esSnapshot"
+	StepTypeAssertTopology = "assertTopology"
+)
+
+// Conditions a waitFor step can wait for
+const (
+	ConditionRollout         = "rollout"
+	ConditionNoPendingChange = "no-pending-change"
+	ConditionExportingPhase  = "exporting-phase"
+	ConditionExporterStatus  = "exporter-status"
+	ConditionESHealth        = "elasticsearch-health"
+)
+
+// Elasticsearch snapshot actions
+const (
+	SnapshotActionConfigure = "configure"
+	SnapshotActionCreate    = "create"
+	SnapshotActionRestore   = "restore"
+)
+
+// DefaultStepTimeout applies to steps without a timeout
+const DefaultStepTimeout = 10 * time.Minute
+
+// Runbook is an operational procedure as a reviewable list of steps
+type Runbook struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Steps       []Step `yaml:"steps"`
+}
+
+// Step is a single action of a runbook against the cluster of one region
+type Step struct {
+	Name string `yaml:"name"`
+	// Region is the region id the step runs against, the gateway of that region for actuator calls
+	Region  int           `yaml:"region"`
+	Timeout time.Duration `yaml:"timeout"`
+
+	Actuator       *ActuatorStep       `yaml:"actuator,omitempty"`
+	HelmUpgrade    *HelmUpgradeStep    `yaml:"helmUpgrade,omitempty"`
+	KubectlApply   *KubectlApplyStep   `yaml:"kubectlApply,omitempty"`
+	WaitFor        *WaitForStep        `yaml:"waitFor,omitempty"`
+	ESSnapshot     *ESSnapshotStep     `yaml:"esSnapshot,omitempty"`
+	AssertTopology *AssertTopologyStep `yaml:"assertTopology,omitempty"`
+}
+
+// ActuatorStep calls the management API of the gateway
+type ActuatorStep struct {
+	Method string            `yaml:"method"`
+	Path   string            `yaml:"path"`
+	Query  map[string]string `yaml:"query"`
+	Body   interface{}       `yaml:"body"`
+	// WaitForChange follows the cluster change started by the call until it completed
+	WaitForChange bool `yaml:"waitForChange"`
+	// Force sends force=true, only for PATCH /actuator/cluster naming the LostRegion, a force key in Query is rejected
+	Force bool `yaml:"force"`
+	// LostRegion is the region that is gone, the forced call is only sent once its force removal gate passed
+	LostRegion *int `yaml:"lostRegion,omitempty"`
+	// RemoveLostRegionBrokers derives the body from the cluster members, removing every broker of LostRegion
+	RemoveLostRegionBrokers bool `yaml:"removeLostRegionBrokers"`
+}
+
+// HelmUpgrade installs or upgrades the Camunda release of the region with the given values on top of the defaults
+type HelmUpgradeStep struct {
+	ValuesFiles []string          `yaml:"valuesFiles"`
+	Set         map[string]string `yaml:"set"`
+	SetString   map[string]string `yaml:"setString"`
+}
+
+// KubectlApplyStep applies a manifest in the namespace of the region
+type KubectlApplyStep struct {
+	Manifest string `yaml:"manifest"`
+}
+
+// WaitForStep polls until the condition holds or the step times out
+type WaitForStep struct {
+	Condition string `yaml:"condition"`
+	// Resource is the workload for rollout, e.g. statefulset/camunda-zeebe
+	Resource string `yaml:"resource"`
+	// Exporter is the exporter id for exporter-status
+	Exporter string `yaml:"exporter"`
+	// Value is the expected exporting phase or exporter status
+	Value    string        `yaml:"value"`
+	Interval time.Duration `yaml:"interval"`
+}
+
+// ESSnapshotStep configures the snapshot repository, creates or restores an Elasticsearch snapshot
+type ESSnapshotStep struct {
+	Action string `yaml:"action"`
+	Name   string `yaml:"name"`
+}
+
+// AssertTopologyStep asserts the topology as seen by the gateway of the region, zero values are not checked
+type AssertTopologyStep struct {
+	Brokers              int  `yaml:"brokers"`
+	Partitions           int  `yaml:"partitions"`
+	ReplicationFactor    int  `yaml:"replicationFactor"`
+	ReplicasInEachRegion bool `yaml:"replicasInEachRegion"`
+	// LostRegion asserts no broker of the region is left in the topology
+	LostRegion *int `yaml:"lostRegion,omitempty"`
+}
+
+// LoadRunbook reads and validates a runbook from a YAML file
+func LoadRunbook(path string) (Runbook, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Runbook{}, err
+	}
+	return ParseRunbook(content)
+}
+
+// ParseRunbook decodes and validates a runbook, unknown fields are rejected to catch typos
+func ParseRunbook(content []byte) (Runbook, error) {
+	var runbook Runbook
+
+	decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&runbook); err != nil {
+		return Runbook{}, fmt.Errorf("failed to parse runbook: %w", err)
+	}
+
+	return runbook, runbook.Validate()
+}
+
+// Validate checks that every step is complete before the first one runs
+func (r Runbook) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("runbook has no name")
+	}
+	if len(r.Steps) == 0 {
+		return fmt.Errorf("runbook %s has no steps", r.Name)
+	}
+
+	names := map[string]bool{}
+	for i, step := range r.Steps {
+		if step.Name == "" {
+			return fmt.Errorf("step %d has no name", i+1)
+		}
+		if names[step.Name] {
+			return fmt.Errorf("step name %q is used more than once", step.Name)
+		}
+		names[step.Name] = true
+
+		if err := step.Validate(); err != nil {
+			return fmt.Errorf("step %q: %w", step.Name, err)
+		}
+	}
+	return nil
+}
+
+// Type returns the type of the step derived from the block it sets, empty if it sets none or more than one
+func (s Step) Type() string {
+	var types []string
+	if s.Actuator != nil {
+		types = append(types, StepTypeActuator)
+	}
+	if s.HelmUpgrade != nil {
+		types = append(types, StepTypeHelmUpgrade)
+	}
+	if s.KubectlApply != nil {
+		types = append(types, StepTypeKubectlApply)
+	}
+	if s.WaitFor != nil {
+		types = append(types, StepTypeWaitFor)
+	}
+	if s.ESSnapshot != nil {
+		types = append(types, StepTypeESSnapshot)
+	}
+	if s.AssertTopology != nil {
+		types = append(types, StepTypeAssertTopology)
+	}
+
+	if len(types) != 1 {
+		return ""
+	}
+	return types[0]
+}
+
+// Validate checks the step sets exactly one type with all its required fields
+func (s Step) Validate() error {
+	if s.Region < 0 {
+		return fmt.Errorf("region %d is not a region id", s.Region)
+	}
+	if s.Timeout < 0 {
+		return fmt.Errorf("negative timeout %s", s.Timeout)
+	}
+
+	switch s.Type() {
+	case StepTypeActuator:
+		if s.Actuator.Method == "" || !strings.HasPrefix(s.Actuator.Path, "/actuator/") {
+			return fmt.Errorf("actuator step needs a method and a path below /actuator/")
+		}
+		for key := range s.Actuator.Query {
+			if strings.EqualFold(key, "force") {
+				return fmt.Errorf("set force on the actuator step instead of the query")
+			}
+		}
+		if s.Actuator.Force != (s.Actuator.LostRegion != nil) {
+			return fmt.Errorf("force requires lostRegion to name the region that is gone and vice versa")
+		}
+		if s.Actuator.Force && (!strings.EqualFold(s.Actuator.Method, "PATCH") || s.Actuator.Path != "/actuator/cluster") {
+			return fmt.Errorf("force is only supported for PATCH /actuator/cluster")
+		}
+		if s.Actuator.RemoveLostRegionBrokers && (s.Actuator.LostRegion == nil || s.Actuator.Body != nil || s.Actuator.Path != "/actuator/cluster") {
+			return fmt.Errorf("removeLostRegionBrokers requires lostRegion, the path /actuator/cluster and no body")
+		}
+	case StepTypeHelmUpgrade:
+	case StepTypeKubectlApply:
+		if s.KubectlApply.Manifest == "" {
+			return fmt.Errorf("kubectlApply step needs a manifest")
+		}
+	case StepTypeWaitFor:
+		switch s.WaitFor.Condition {
+		case ConditionRollout:
+			if s.WaitFor.Resource == "" {
+				return fmt.Errorf("rollout condition needs a resource")
+			}
+		case ConditionExportingPhase:
+			if s.WaitFor.Value == "" {
+				return fmt.Errorf("exporting-phase condition needs a value")
+			}
+		case ConditionExporterStatus:
+			if s.WaitFor.Exporter == "" || s.WaitFor.Value == "" {
+				return fmt.Errorf("exporter-status condition needs an exporter and a value")
+			}
+		case ConditionNoPendingChange, ConditionESHealth:
+		default:
+			return fmt.Errorf("unknown condition %q", s.WaitFor.Condition)
+		}
+	case StepTypeESSnapshot:
+		switch s.ESSnapshot.Action {
+		case SnapshotActionConfigure:
+		case SnapshotActionCreate, SnapshotActionRestore:
+			if s.ESSnapshot.Name == "" {
+				return fmt.Errorf("%s snapshot needs a name", s.ESSnapshot.Action)
+			}
+		default:
+			return fmt.Errorf("unknown snapshot action %q", s.ESSnapshot.Action)
+		}
+	case StepTypeAssertTopology:
+	default:
+		return fmt.Errorf("step has to set exactly one of actuator, helmUpgrade, kubectlApply, waitFor, esSnapshot, assertTopology")
+	}
+	return nil
+}
+
+// EffectiveTimeout returns the timeout of the step or DefaultStepTimeout
+func (s Step) EffectiveTimeout() time.Duration {
+	if s.Timeout > 0 {
+		return s.Timeout
+	}
+	return DefaultStepTimeout
+}
+
+// Describe renders what the step does, used for logging and dry runs
+func (s Step) Describe() string {
+	switch s.Type() {
+	case StepTypeActuator:
+		description := fmt.Sprintf("%s %s", s.Actuator.Method, s.Actuator.Path)
+		if len(s.Actuator.Query) > 0 {
+			description += fmt.Sprintf(" %v", s.Actuator.Query)
+		}
+		if s.Actuator.Body != nil {
+			description += fmt.Sprintf(" body %v", s.Actuator.Body)
+		}
+		if s.Actuator.RemoveLostRegionBrokers {
+			description += fmt.Sprintf(" removing the brokers of region %d", *s.Actuator.LostRegion)
+		}
+		if s.Actuator.WaitForChange {
+			description += ", wait for the cluster change"
+		}
+		return description
+	case StepTypeHelmUpgrade:
+		return fmt.Sprintf("helm upgrade with values %v, set %v, set-string %v", s.HelmUpgrade.ValuesFiles, s.HelmUpgrade.Set, s.HelmUpgrade.SetString)
+	case StepTypeKubectlApply:
+		return fmt.Sprintf("kubectl apply -f %s", s.KubectlApply.Manifest)
+	case StepTypeWaitFor:
+		return fmt.Sprintf("wait for %s %s%s %s", s.WaitFor.Condition, s.WaitFor.Resource, s.WaitFor.Exporter, s.WaitFor.Value)
+	case StepTypeESSnapshot:
+		return fmt.Sprintf("%s Elasticsearch snapshot %s", s.ESSnapshot.Action, s.ESSnapshot.Name)
+	case StepTypeAssertTopology:
+		return fmt.Sprintf("assert topology %+v", *s.AssertTopology)
+	}
+	return "invalid step"
+}
+
+// LostRegionBrokers returns the broker ids of the region following the nodeId % regions scheme of the Helm chart
+func LostRegionBrokers(brokerIds []int, regions, region int) []int {
+	var lost []int
+	for _, id := range brokerIds {
+		if regions > 0 && id%regions == region {
+			lost = append(lost, id)
+		}
+	}
+	return lost
+}
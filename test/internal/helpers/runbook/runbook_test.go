@@ -0,0 +1,101 @@
+package runbookHelpers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"multiregiontests/internal/helpers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadShippedRunbooks(t *testing.T) {
+	runbook, err := LoadRunbook("../../../runbooks/failover.yml")
+	require.NoError(t, err)
+	require.Equal(t, "failover", runbook.Name)
+	require.Equal(t, StepTypeActuator, runbook.Steps[0].Type())
+	require.Equal(t, 5*time.Minute, runbook.Steps[0].Timeout)
+	require.True(t, runbook.Steps[0].Actuator.Force)
+	require.Equal(t, 1, *runbook.Steps[0].Actuator.LostRegion)
+	require.True(t, runbook.Steps[0].Actuator.RemoveLostRegionBrokers)
+}
+
+func TestParseRunbookRejectsInvalidSteps(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		content string
+	}{
+		{"unknown field", "name: x\nsteps:\n  - name: a\n    waitFor: {condition: rollout, resource: sts/x}\n    retries: 3\n"},
+		{"no type", "name: x\nsteps:\n  - name: a\n"},
+		{"two types", "name: x\nsteps:\n  - name: a\n    kubectlApply: {manifest: a.yml}\n    assertTopology: {brokers: 8}\n"},
+		{"duplicate name", "name: x\nsteps:\n  - name: a\n    assertTopology: {}\n  - name: a\n    assertTopology: {}\n"},
+		{"unknown condition", "name: x\nsteps:\n  - name: a\n    waitFor: {condition: sunshine}\n"},
+		{"force without lost region", "name: x\nsteps:\n  - name: a\n    actuator: {method: PATCH, path: /actuator/cluster, force: true}\n"},
+		{"lost region without force", "name: x\nsteps:\n  - name: a\n    actuator: {method: PATCH, path: /actuator/cluster, lostRegion: 1}\n"},
+		{"force in query", "name: x\nsteps:\n  - name: a\n    actuator: {method: PATCH, path: /actuator/cluster, query: {force: \"true\"}, lostRegion: 1}\n"},
+		{"force in query spelled differently", "name: x\nsteps:\n  - name: a\n    actuator: {method: PATCH, path: /actuator/cluster, query: {Force: \"1\"}}\n"},
+		{"force outside cluster", "name: x\nsteps:\n  - name: a\n    actuator: {method: POST, path: /actuator/exporters/x/disable, force: true, lostRegion: 1}\n"},
+		{"derived removal without lost region", "name: x\nsteps:\n  - name: a\n    actuator: {method: PATCH, path: /actuator/cluster, removeLostRegionBrokers: true}\n"},
+		{"derived removal with body", "name: x\nsteps:\n  - name: a\n    actuator: {method: PATCH, path: /actuator/cluster, lostRegion: 1, removeLostRegionBrokers: true, body: {brokers: {remove: [1]}}}\n"},
+		{"path outside actuator", "name: x\nsteps:\n  - name: a\n    actuator: {method: GET, path: /v2/topology}\n"},
+		{"restore without name", "name: x\nsteps:\n  - name: a\n    esSnapshot: {action: restore}\n"},
+		{"invalid timeout", "name: x\nsteps:\n  - name: a\n    timeout: soon\n    assertTopology: {}\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseRunbook([]byte(tc.content))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestEngineChecksRegionsOfEnvironment(t *testing.T) {
+	runbook, err := ParseRunbook([]byte(`
+name: failover
+steps:
+  - name: remove
+    actuator: {method: PATCH, path: /actuator/cluster, force: true, lostRegion: 0}
+`))
+	require.NoError(t, err)
+
+	engine := NewEngine(runbook, Environment{Regions: make([]helpers.Cluster, 2), DryRun: true})
+	require.ErrorContains(t, engine.CheckEnvironment(), "names region 0 as lost")
+
+	runbook.Steps[0].Region = 2
+	require.ErrorContains(t, engine.CheckEnvironment(), "only 2 regions")
+}
+
+func TestEngineDryRunDoesNotTouchClusters(t *testing.T) {
+	runbook, err := LoadRunbook("../../../runbooks/failover.yml")
+	require.NoError(t, err)
+
+	// Without kubeconfigs any cluster access fails, so the dry run only passes if it stays offline
+	NewEngine(runbook, Environment{Regions: make([]helpers.Cluster, 2), DryRun: true}).Run(t)
+}
+
+func TestStepTimesOut(t *testing.T) {
+	step := Step{Name: "upgrade", Timeout: 10 * time.Millisecond, HelmUpgrade: &HelmUpgradeStep{}}
+	upgrade := func(delay time.Duration) HelmUpgradeFunc {
+		return func(context.Context, *testing.T, int, []string, map[string]string, map[string]string) {
+			time.Sleep(delay)
+		}
+	}
+
+	slow := NewEngine(Runbook{}, Environment{HelmUpgrade: upgrade(50 * time.Millisecond)})
+	require.ErrorContains(t, slow.runStepWithTimeout(t, step), "did not finish within its timeout of 10ms")
+
+	fast := NewEngine(Runbook{}, Environment{HelmUpgrade: upgrade(0)})
+	step.Timeout = 0
+	require.NoError(t, fast.runStepWithTimeout(t, step))
+}
+
+func TestLostRegionBrokers(t *testing.T) {
+	require.Equal(t, []int{1, 3, 5, 7}, LostRegionBrokers([]int{0, 1, 2, 3, 4, 5, 6, 7}, 2, 1))
+	require.Equal(t, []int{1, 4, 7}, LostRegionBrokers([]int{0, 1, 2, 3, 4, 5, 6, 7, 8}, 3, 1))
+	require.Empty(t, LostRegionBrokers([]int{0, 2, 4, 6}, 2, 1))
+}
+
+func TestRetries(t *testing.T) {
+	require.Equal(t, 20, retries(5*time.Minute, 15*time.Second))
+	require.Equal(t, 1, retries(time.Second, 15*time.Second))
+}
@@ -0,0 +1,186 @@
+package valuesHelpers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPath is the list of environment variables of the Orchestration Cluster
+const EnvPath = "orchestration.env"
+
+// Builder combines values files the way Helm does, maps are merged and lists replaced
+// Lists of named objects like orchestration.env can be merged by name
+type Builder struct {
+	values map[string]interface{}
+}
+
+// NewBuilder creates an empty Builder
+func NewBuilder() *Builder {
+	return &Builder{values: map[string]interface{}{}}
+}
+
+// LoadFiles merges the values files in order, later files win
+func (b *Builder) LoadFiles(paths ...string) error {
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := b.Load(content); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// Load merges the values of a YAML document
+func (b *Builder) Load(content []byte) error {
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return fmt.Errorf("failed to parse values: %w", err)
+	}
+	mergeValues(b.values, values)
+	return nil
+}
+
+// Get returns the value at a dotted path, ok is false if it is not set
+func (b *Builder) Get(path string) (interface{}, bool) {
+	var current interface{} = b.values
+	for _, key := range strings.Split(path, ".") {
+		m, isMap := current.(map[string]interface{})
+		if !isMap {
+			return nil, false
+		}
+		if current, isMap = m[key]; !isMap {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// MergeNamed merges entries into the list of objects at path, identified by their key field
+// An entry replaces the object with the same key where it is, entries with a new key are appended
+func (b *Builder) MergeNamed(path, key string, entries ...map[string]interface{}) error {
+	var list []interface{}
+	if current, ok := b.Get(path); ok && current != nil {
+		if list, ok = current.([]interface{}); !ok {
+			return fmt.Errorf("%s is not a list", path)
+		}
+	}
+
+	index := map[interface{}]int{}
+	for i, item := range list {
+		object, ok := item.(map[string]interface{})
+		if !ok || object[key] == nil {
+			return fmt.Errorf("%s[%d] has no %s", path, i, key)
+		}
+		index[object[key]] = i
+	}
+
+	merged := append([]interface{}{}, list...)
+	for _, entry := range entries {
+		if entry[key] == nil {
+			return fmt.Errorf("entry for %s has no %s", path, key)
+		}
+		if i, ok := index[entry[key]]; ok {
+			merged[i] = entry
+			continue
+		}
+		index[entry[key]] = len(merged)
+		merged = append(merged, entry)
+	}
+
+	return b.set(path, merged)
+}
+
+// SetEnv sets environment variables of the Orchestration Cluster by name
+func (b *Builder) SetEnv(env ...EnvVar) error {
+	entries := make([]map[string]interface{}, len(env))
+	for i, e := range env {
+		entries[i] = map[string]interface{}{"name": e.Name, "value": e.Value}
+		if e.ValueFrom != nil {
+			entries[i]["valueFrom"] = e.ValueFrom
+		}
+	}
+	return b.MergeNamed(EnvPath, "name", entries...)
+}
+
+// Env returns the environment variables of the Orchestration Cluster, nil if there are none
+func (b *Builder) Env() ([]EnvVar, error) {
+	current, ok := b.Get(EnvPath)
+	if !ok || current == nil {
+		return nil, nil
+	}
+	content, err := yaml.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+	var env []EnvVar
+	if err := yaml.Unmarshal(content, &env); err != nil {
+		return nil, fmt.Errorf("%s: %w", EnvPath, err)
+	}
+	return env, nil
+}
+
+// Render renders all values as a values file
+func (b *Builder) Render() ([]byte, error) {
+	return yaml.Marshal(b.values)
+}
+
+// Overlay returns only the given paths of the values, to pass the merged lists to Helm on top of the values files
+func (b *Builder) Overlay(paths ...string) (map[string]interface{}, error) {
+	overlay := NewBuilder()
+	for _, path := range paths {
+		value, ok := b.Get(path)
+		if !ok {
+			return nil, fmt.Errorf("%s is not set", path)
+		}
+		if err := overlay.set(path, value); err != nil {
+			return nil, err
+		}
+	}
+	return overlay.values, nil
+}
+
+// RenderOverlay renders Overlay as a values file
+func (b *Builder) RenderOverlay(paths ...string) ([]byte, error) {
+	overlay, err := b.Overlay(paths...)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(overlay)
+}
+
+// set stores a value at a dotted path, creating the maps on the way
+func (b *Builder) set(path string, value interface{}) error {
+	keys := strings.Split(path, ".")
+	current := b.values
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key]
+		if !ok || next == nil {
+			next = map[string]interface{}{}
+			current[key] = next
+		}
+		if current, ok = next.(map[string]interface{}); !ok {
+			return fmt.Errorf("%s of %s is not a map", key, path)
+		}
+	}
+	current[keys[len(keys)-1]] = value
+	return nil
+}
+
+// mergeValues merges src into dst like Helm merges values files
+func mergeValues(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeValues(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
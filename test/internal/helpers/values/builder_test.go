@@ -0,0 +1,88 @@
+package valuesHelpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const exampleValues = "../../../../aws/dual-region/kubernetes/camunda-values.yml"
+
+func envNames(t *testing.T, b *Builder) []string {
+	env, ok := b.Get(EnvPath)
+	require.True(t, ok)
+	var names []string
+	for _, entry := range env.([]interface{}) {
+		names = append(names, entry.(map[string]interface{})["name"].(string))
+	}
+	return names
+}
+
+func TestSetEnvAppendsAndReplacesByName(t *testing.T) {
+	b := NewBuilder()
+	require.NoError(t, b.LoadFiles(exampleValues))
+	before := envNames(t, b)
+
+	require.NoError(t, b.SetEnv(
+		EnvVar{Name: "CAMUNDA_DATABASE_SCHEMAMANAGER_CREATESCHEMA", Value: "false"},
+		EnvVar{Name: "CAMUNDA_DATA_SNAPSHOTPERIOD", Value: "1m"},
+	))
+
+	after := envNames(t, b)
+	require.Equal(t, append(before, "CAMUNDA_DATABASE_SCHEMAMANAGER_CREATESCHEMA"), after)
+
+	env, _ := b.Get(EnvPath)
+	for _, entry := range env.([]interface{}) {
+		if entry.(map[string]interface{})["name"] == "CAMUNDA_DATA_SNAPSHOTPERIOD" {
+			require.Equal(t, "1m", entry.(map[string]interface{})["value"])
+		}
+	}
+}
+
+func TestLoadMergesMapsAndReplacesLists(t *testing.T) {
+	b := NewBuilder()
+	require.NoError(t, b.Load([]byte("global:\n  multiregion:\n    regions: 2\n  elasticsearch:\n    enabled: true\nlist: [a, b]\n")))
+	require.NoError(t, b.Load([]byte("global:\n  multiregion:\n    regionId: 1\nlist: [c]\n")))
+
+	regions, _ := b.Get("global.multiregion.regions")
+	require.Equal(t, 2, regions)
+	regionId, _ := b.Get("global.multiregion.regionId")
+	require.Equal(t, 1, regionId)
+	list, _ := b.Get("list")
+	require.Equal(t, []interface{}{"c"}, list)
+}
+
+func TestMergeNamedAnyPath(t *testing.T) {
+	b := NewBuilder()
+	require.NoError(t, b.MergeNamed("connectors.inbound.sidecars", "id", map[string]interface{}{"id": "a", "port": 1}))
+	require.NoError(t, b.MergeNamed("connectors.inbound.sidecars", "id", map[string]interface{}{"id": "a", "port": 2}, map[string]interface{}{"id": "b"}))
+
+	content, err := b.RenderOverlay("connectors.inbound.sidecars")
+	require.NoError(t, err)
+	var overlay struct {
+		Connectors struct {
+			Inbound struct {
+				Sidecars []struct {
+					Id   string `yaml:"id"`
+					Port int    `yaml:"port"`
+				} `yaml:"sidecars"`
+			} `yaml:"inbound"`
+		} `yaml:"connectors"`
+	}
+	require.NoError(t, yaml.Unmarshal(content, &overlay))
+	require.Len(t, overlay.Connectors.Inbound.Sidecars, 2)
+	require.Equal(t, 2, overlay.Connectors.Inbound.Sidecars[0].Port)
+	require.Equal(t, "b", overlay.Connectors.Inbound.Sidecars[1].Id)
+}
+
+func TestMergeNamedRejectsInvalidLists(t *testing.T) {
+	b := NewBuilder()
+	require.NoError(t, b.Load([]byte("orchestration:\n  clusterSize: '8'\n  env:\n    - value: x\n")))
+
+	require.ErrorContains(t, b.SetEnv(EnvVar{Name: "A", Value: "b"}), "orchestration.env[0] has no name")
+	require.ErrorContains(t, b.MergeNamed("orchestration.clusterSize", "name"), "is not a list")
+	require.ErrorContains(t, b.MergeNamed("orchestration.clusterSize.env", "name", map[string]interface{}{"name": "A"}), "is not a map")
+	_, err := b.Overlay("orchestration.missing")
+	require.ErrorContains(t, err, "is not set")
+}
@@ -0,0 +1,192 @@
+package valuesHelpers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// regionExporterEnv matches the env entries of the region specific Camunda exporters
+var regionExporterEnv = regexp.MustCompile(`^ZEEBE_BROKER_EXPORTERS_CAMUNDAREGION(\d+)_`)
+
+// Stack is the values files of a multi-region installation in the order they are passed to Helm
+type Stack struct {
+	// Common values apply to every region
+	Common []string
+	// Regions holds the region specific values file, indexed by region id, empty if a region has none
+	Regions []string
+	// Config applies the overlay of the tests before the checks, the files are checked as they are if nil
+	Config *Config
+}
+
+// Lint checks the multi-region invariants of the merged values of every region, without talking to a cluster
+// All findings are returned joined, nil if the stack is fine
+func (s Stack) Lint() error {
+	var findings []error
+	regionIds := map[int]string{}
+
+	for region := range s.Regions {
+		files := append(append([]string{}, s.Common...), s.Regions[region])
+		if s.Regions[region] == "" {
+			files = files[:len(files)-1]
+		}
+
+		values := NewBuilder()
+		if err := values.LoadFiles(files...); err != nil {
+			return err
+		}
+		if s.Config != nil {
+			if _, err := s.Config.ApplyOverlay(values, region); err != nil {
+				return err
+			}
+		}
+		rendered, err := values.Render()
+		if err != nil {
+			return err
+		}
+		if strings.Contains(string(rendered), Placeholder) {
+			findings = append(findings, fmt.Errorf("region %d: values still contain %s", region, Placeholder))
+		}
+		if namespaces := ExampleNamespaceReferences(string(rendered)); s.Config != nil && len(namespaces) > 0 {
+			findings = append(findings, fmt.Errorf("region %d: values still reference the example namespaces %v", region, namespaces))
+		}
+
+		if s.Regions[region] != "" {
+			content, err := os.ReadFile(s.Regions[region])
+			if err != nil {
+				return err
+			}
+			regionId, ok, err := ValuesRegionId(content)
+			if err != nil {
+				return fmt.Errorf("%s: %w", s.Regions[region], err)
+			}
+			if ok {
+				if other, taken := regionIds[regionId]; taken {
+					findings = append(findings, fmt.Errorf("%s and %s both set regionId %d", other, s.Regions[region], regionId))
+				}
+				regionIds[regionId] = s.Regions[region]
+				if regionId != region {
+					findings = append(findings, fmt.Errorf("%s sets regionId %d but is the values file of region %d", s.Regions[region], regionId, region))
+				}
+			}
+		}
+
+		for _, finding := range lintRegion(values, len(s.Regions)) {
+			findings = append(findings, fmt.Errorf("region %d: %w", region, finding))
+		}
+	}
+
+	return errors.Join(dedupe(findings)...)
+}
+
+// lintRegion checks the merged values of a single region
+func lintRegion(values *Builder, regionCount int) []error {
+	var findings []error
+
+	regions, err := intValue(values, "global.multiregion.regions")
+	if err != nil {
+		return append(findings, err)
+	}
+	if regions != regionCount {
+		findings = append(findings, fmt.Errorf("global.multiregion.regions is %d, but the stack has values for %d regions", regions, regionCount))
+	}
+
+	clusterSize, err := intValue(values, "orchestration.clusterSize")
+	if err != nil {
+		findings = append(findings, err)
+	} else if regions > 0 && clusterSize%regions != 0 {
+		findings = append(findings, fmt.Errorf("orchestration.clusterSize %d is not divisible by %d regions", clusterSize, regions))
+	}
+
+	replicationFactor, err := intValue(values, "orchestration.replicationFactor")
+	if err != nil {
+		findings = append(findings, err)
+	} else if regions > 0 && (replicationFactor < regions || replicationFactor%regions != 0) {
+		findings = append(findings, fmt.Errorf("orchestration.replicationFactor %d can't place the same number of replicas in each of %d regions", replicationFactor, regions))
+	} else if clusterSize > 0 && replicationFactor > clusterSize {
+		findings = append(findings, fmt.Errorf("orchestration.replicationFactor %d exceeds orchestration.clusterSize %d", replicationFactor, clusterSize))
+	}
+
+	for _, exporter := range []string{"camunda", "zeebe"} {
+		path := fmt.Sprintf("orchestration.exporters.%s.enabled", exporter)
+		if enabled, ok := values.Get(path); !ok || enabled != false {
+			findings = append(findings, fmt.Errorf("%s has to be false, the region specific exporters replace it", path))
+		}
+	}
+
+	findings = append(findings, lintRegionExporters(values, regions)...)
+	return findings
+}
+
+// lintRegionExporters checks there is exactly one Camunda exporter per region and none for regions that don't exist
+func lintRegionExporters(values *Builder, regions int) []error {
+	var findings []error
+
+	env, _ := values.Get(EnvPath)
+	list, _ := env.([]interface{})
+	names := map[string]int{}
+	exporters := map[int]bool{}
+	for _, item := range list {
+		entry, _ := item.(map[string]interface{})
+		name, _ := entry["name"].(string)
+		names[name]++
+		if match := regionExporterEnv.FindStringSubmatch(name); match != nil {
+			region, _ := strconv.Atoi(match[1])
+			exporters[region] = true
+		}
+	}
+
+	for name, count := range names {
+		if count > 1 {
+			findings = append(findings, fmt.Errorf("%s is set %d times in %s", name, count, EnvPath))
+		}
+	}
+	for region := 0; region < regions; region++ {
+		for _, name := range []string{ExporterClassNameEnv(region), ExporterConnectURLEnv(region)} {
+			if names[name] == 0 {
+				findings = append(findings, fmt.Errorf("%s is missing, every region needs its Camunda exporter", name))
+			}
+		}
+	}
+	for region := range exporters {
+		if region >= regions {
+			findings = append(findings, fmt.Errorf("exporter of region %d is configured, but there are only %d regions", region, regions))
+		}
+	}
+	return findings
+}
+
+// intValue reads a number the chart accepts as int or string
+func intValue(values *Builder, path string) (int, error) {
+	value, ok := values.Get(path)
+	if !ok {
+		return 0, fmt.Errorf("%s is not set", path)
+	}
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case string:
+		number, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("%s is not a number: %q", path, v)
+		}
+		return number, nil
+	}
+	return 0, fmt.Errorf("%s is not a number: %v", path, value)
+}
+
+// dedupe drops repeated findings, the common values are checked once per region
+func dedupe(findings []error) []error {
+	seen := map[string]bool{}
+	var unique []error
+	for _, finding := range findings {
+		if !seen[finding.Error()] {
+			seen[finding.Error()] = true
+			unique = append(unique, finding)
+		}
+	}
+	return unique
+}
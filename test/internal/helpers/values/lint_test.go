@@ -0,0 +1,90 @@
+package valuesHelpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const exampleDir = "../../../../aws/dual-region/kubernetes"
+
+func exampleStack(common ...string) Stack {
+	return Stack{
+		Common:  append([]string{filepath.Join(exampleDir, "camunda-values.yml")}, common...),
+		Regions: []string{filepath.Join(exampleDir, "region0/camunda-values.yml"), filepath.Join(exampleDir, "region1/camunda-values.yml")},
+	}
+}
+
+func writeValues(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "values.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLintShippedValues(t *testing.T) {
+	// The example files leave the generated values to the deployment, which renders them like the config
+	config := &Config{Release: "camunda", Namespaces: []string{"c8-cluster-0", "c8-cluster-1"}, ClusterSize: 8}
+
+	stack := exampleStack()
+	stack.Config = config
+	require.NoError(t, stack.Lint())
+
+	multiTenancy := exampleStack("../../../fixtures/multi-tenancy.yml")
+	multiTenancy.Config = config
+	require.NoError(t, multiTenancy.Lint())
+
+	migration := exampleStack()
+	migration.Common[0] = filepath.Join(exampleDir, "camunda-values-migration.yml")
+	migration.Config = config
+	require.NoError(t, migration.Lint())
+
+	require.ErrorContains(t, exampleStack().Lint(), "still contain PLACEHOLDER")
+}
+
+func TestLintThreeRegionFixture(t *testing.T) {
+	stack := exampleStack("../../../fixtures/three-regions.yml")
+	stack.Regions = append(stack.Regions, "")
+
+	// The fixture leaves the generated values to the tests
+	require.ErrorContains(t, stack.Lint(), "still contain PLACEHOLDER")
+
+	stack.Config = &Config{Release: "camunda", Namespaces: []string{"a", "b", "c"}, ClusterSize: 9}
+	require.NoError(t, stack.Lint())
+}
+
+func TestLintFindsBrokenValues(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		overlay string
+		regions []string
+		finding string
+	}{
+		{"cluster size", "orchestration:\n  clusterSize: '7'\n", nil, "clusterSize 7 is not divisible by 2 regions"},
+		{"replication factor", "orchestration:\n  replicationFactor: '3'\n", nil, "replicationFactor 3 can't place the same number of replicas"},
+		{"regions", "global:\n  multiregion:\n    regions: 3\n", nil, "global.multiregion.regions is 3"},
+		{"default exporter", "orchestration:\n  exporters:\n    camunda:\n      enabled: true\n", nil, "orchestration.exporters.camunda.enabled has to be false"},
+		{"missing exporter", "orchestration:\n  env:\n    - name: ZEEBE_BROKER_EXPORTERS_CAMUNDAREGION0_CLASSNAME\n      value: x\n    - name: ZEEBE_BROKER_EXPORTERS_CAMUNDAREGION0_ARGS_CONNECT_URL\n      value: x\n",
+			nil, "ZEEBE_BROKER_EXPORTERS_CAMUNDAREGION1_CLASSNAME is missing"},
+		{"extra exporter", "orchestration:\n  env:\n    - name: ZEEBE_BROKER_EXPORTERS_CAMUNDAREGION0_CLASSNAME\n      value: x\n    - name: ZEEBE_BROKER_EXPORTERS_CAMUNDAREGION0_ARGS_CONNECT_URL\n      value: x\n" +
+			"    - name: ZEEBE_BROKER_EXPORTERS_CAMUNDAREGION1_CLASSNAME\n      value: x\n    - name: ZEEBE_BROKER_EXPORTERS_CAMUNDAREGION1_ARGS_CONNECT_URL\n      value: x\n    - name: ZEEBE_BROKER_EXPORTERS_CAMUNDAREGION2_CLASSNAME\n      value: x\n",
+			nil, "exporter of region 2 is configured"},
+		{"placeholder", "orchestration:\n  env:\n    - name: X\n      value: PLACEHOLDER\n", nil, "still contain PLACEHOLDER"},
+		{"duplicate region id", "", []string{"region0/camunda-values.yml", "region0/camunda-values.yml"}, "both set regionId 0"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			stack := exampleStack()
+			if tc.overlay != "" {
+				stack.Common = append(stack.Common, writeValues(t, tc.overlay))
+			}
+			if tc.regions != nil {
+				stack.Regions = nil
+				for _, region := range tc.regions {
+					stack.Regions = append(stack.Regions, filepath.Join(exampleDir, region))
+				}
+			}
+			require.ErrorContains(t, stack.Lint(), tc.finding)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package valuesHelpers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvRename maps a deprecated env entry to its equivalent of the unified configuration
+type EnvRename struct {
+	From string
+	To   string
+}
+
+// EnvRenames is the mapping table of deprecated ZEEBE_* env entries to their 8.9+ CAMUNDA_* names
+// Extend it whenever a release note deprecates another Zeebe setting
+var EnvRenames = []EnvRename{
+	{From: "ZEEBE_BROKER_CLUSTER_INITIALCONTACTPOINTS", To: "CAMUNDA_CLUSTER_INITIALCONTACTPOINTS"},
+	{From: "ZEEBE_BROKER_CLUSTER_CLUSTERSIZE", To: "CAMUNDA_CLUSTER_SIZE"},
+	{From: "ZEEBE_BROKER_CLUSTER_PARTITIONSCOUNT", To: "CAMUNDA_CLUSTER_PARTITIONCOUNT"},
+	{From: "ZEEBE_BROKER_CLUSTER_REPLICATIONFACTOR", To: "CAMUNDA_CLUSTER_REPLICATIONFACTOR"},
+	{From: "ZEEBE_BROKER_CLUSTER_NODEID", To: "CAMUNDA_CLUSTER_NODEID"},
+	{From: "ZEEBE_BROKER_CLUSTER_CLUSTERNAME", To: "CAMUNDA_CLUSTER_NAME"},
+	{From: "ZEEBE_BROKER_CLUSTER_MEMBERSHIP_PROBETIMEOUT", To: "CAMUNDA_CLUSTER_MEMBERSHIP_PROBETIMEOUT"},
+	{From: "ZEEBE_BROKER_CLUSTER_MEMBERSHIP_PROBEINTERVAL", To: "CAMUNDA_CLUSTER_MEMBERSHIP_PROBEINTERVAL"},
+	{From: "ZEEBE_BROKER_CLUSTER_RAFT_SNAPSHOTREQUESTTIMEOUT", To: "CAMUNDA_CLUSTER_RAFT_SNAPSHOTREQUESTTIMEOUT"},
+	{From: "ZEEBE_BROKER_CLUSTER_MESSAGECOMPRESSION", To: "CAMUNDA_CLUSTER_COMPRESSIONALGORITHM"},
+	{From: "ZEEBE_BROKER_DATA_SNAPSHOTPERIOD", To: "CAMUNDA_DATA_SNAPSHOTPERIOD"},
+}
+
+// currentZeebeEnvPrefixes are ZEEBE_* env entries that are still current, they are not reported
+var currentZeebeEnvPrefixes = []string{"ZEEBE_BROKER_EXPORTERS_"}
+
+// Actions of an EnvChange
+const (
+	EnvRenamed  = "renamed"
+	EnvDropped  = "dropped"
+	EnvConflict = "conflict"
+	EnvUnmapped = "unmapped"
+)
+
+// EnvChange is an entry of the migration report
+type EnvChange struct {
+	Action string
+	From   string
+	To     string
+	Note   string
+}
+
+// MigrationReport lists what MigrateEnv changed in a values file and what needs a manual review
+type MigrationReport struct {
+	File    string
+	Changes []EnvChange
+}
+
+// Changed reports whether the migrated env differs from the original one
+func (r MigrationReport) Changed() bool {
+	for _, change := range r.Changes {
+		if change.Action != EnvUnmapped {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsReview reports whether an entry could not be migrated automatically
+func (r MigrationReport) NeedsReview() bool {
+	for _, change := range r.Changes {
+		if change.Action == EnvConflict || change.Action == EnvUnmapped {
+			return true
+		}
+	}
+	return false
+}
+
+func (r MigrationReport) String() string {
+	var report strings.Builder
+	fmt.Fprintf(&report, "%s: %d change(s)\n", r.File, len(r.Changes))
+	for _, change := range r.Changes {
+		switch change.Action {
+		case EnvUnmapped:
+			fmt.Fprintf(&report, "  %-8s %s: %s\n", change.Action, change.From, change.Note)
+		default:
+			fmt.Fprintf(&report, "  %-8s %s -> %s", change.Action, change.From, change.To)
+			if change.Note != "" {
+				fmt.Fprintf(&report, ": %s", change.Note)
+			}
+			report.WriteString("\n")
+		}
+	}
+	return report.String()
+}
+
+// MigrateEnv renames the deprecated entries of orchestration.env in place, keeping their position and value
+// If an entry exists under both names, the new one wins and the deprecated one is dropped
+func MigrateEnv(b *Builder) (MigrationReport, error) {
+	var report MigrationReport
+
+	env, ok := b.Get(EnvPath)
+	if !ok || env == nil {
+		return report, nil
+	}
+	list, ok := env.([]interface{})
+	if !ok {
+		return report, fmt.Errorf("%s is not a list", EnvPath)
+	}
+
+	renames := map[string]string{}
+	for _, rename := range EnvRenames {
+		renames[rename.From] = rename.To
+	}
+	existing := map[string]map[string]interface{}{}
+	for i, item := range list {
+		entry, ok := item.(map[string]interface{})
+		name, _ := entry["name"].(string)
+		if !ok || name == "" {
+			return report, fmt.Errorf("%s[%d] has no name", EnvPath, i)
+		}
+		existing[name] = entry
+	}
+
+	var migrated []interface{}
+	for _, item := range list {
+		entry := item.(map[string]interface{})
+		name := entry["name"].(string)
+
+		to, deprecated := renames[name]
+		if !deprecated {
+			if isUnmappedZeebeEnv(name) {
+				report.Changes = append(report.Changes, EnvChange{Action: EnvUnmapped, From: name, Note: "deprecated ZEEBE_* entry without known equivalent, review manually"})
+			}
+			migrated = append(migrated, entry)
+			continue
+		}
+
+		if current, ok := existing[to]; ok {
+			change := EnvChange{Action: EnvDropped, From: name, To: to, Note: "already set"}
+			if fmt.Sprint(current["value"], current["valueFrom"]) != fmt.Sprint(entry["value"], entry["valueFrom"]) {
+				change = EnvChange{Action: EnvConflict, From: name, To: to, Note: fmt.Sprintf("both set with different values, kept %s", to)}
+			}
+			report.Changes = append(report.Changes, change)
+			continue
+		}
+
+		renamed := map[string]interface{}{}
+		for key, value := range entry {
+			renamed[key] = value
+		}
+		renamed["name"] = to
+		existing[to] = renamed
+		migrated = append(migrated, renamed)
+		report.Changes = append(report.Changes, EnvChange{Action: EnvRenamed, From: name, To: to})
+	}
+
+	return report, b.set(EnvPath, migrated)
+}
+
+// MigrateEnvFile migrates the env of a values file and returns the overlay with the migrated orchestration.env
+// The overlay is nil if nothing changed
+func MigrateEnvFile(path string) ([]byte, MigrationReport, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, MigrationReport{File: path}, err
+	}
+
+	b := NewBuilder()
+	if err := b.Load(content); err != nil {
+		return nil, MigrationReport{File: path}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	report, err := MigrateEnv(b)
+	report.File = path
+	if err != nil || !report.Changed() {
+		return nil, report, err
+	}
+
+	overlay, err := b.RenderOverlay(EnvPath)
+	return overlay, report, err
+}
+
+func isUnmappedZeebeEnv(name string) bool {
+	if !strings.HasPrefix(name, "ZEEBE_") {
+		return false
+	}
+	for _, prefix := range currentZeebeEnvPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+	return true
+}
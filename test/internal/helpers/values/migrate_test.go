@@ -0,0 +1,89 @@
+package valuesHelpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const deprecatedValues = `orchestration:
+  clusterSize: '8'
+  env:
+    - name: ZEEBE_BROKER_CLUSTER_INITIALCONTACTPOINTS
+      value: a:26502,b:26502
+    - name: ZEEBE_BROKER_EXPORTERS_CAMUNDAREGION0_CLASSNAME
+      value: io.camunda.exporter.CamundaExporter
+    - name: ZEEBE_BROKER_CLUSTER_MEMBERSHIP_PROBETIMEOUT
+      value: 500ms
+    - name: CAMUNDA_CLUSTER_MEMBERSHIP_PROBETIMEOUT
+      value: 1s
+    - name: ZEEBE_BROKER_DATA_SNAPSHOTPERIOD
+      value: 5m
+    - name: CAMUNDA_DATA_SNAPSHOTPERIOD
+      value: 5m
+    - name: ZEEBE_BROKER_GATEWAY_ENABLE
+      value: 'true'
+    - name: JAVA_TOOL_OPTIONS
+      valueFrom:
+        secretKeyRef: {name: java, key: options}
+`
+
+func TestMigrateEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.yml")
+	require.NoError(t, os.WriteFile(path, []byte(deprecatedValues), 0644))
+
+	overlay, report, err := MigrateEnvFile(path)
+	require.NoError(t, err)
+	require.True(t, report.NeedsReview())
+	require.Equal(t, []EnvChange{
+		{Action: EnvRenamed, From: "ZEEBE_BROKER_CLUSTER_INITIALCONTACTPOINTS", To: "CAMUNDA_CLUSTER_INITIALCONTACTPOINTS"},
+		{Action: EnvConflict, From: "ZEEBE_BROKER_CLUSTER_MEMBERSHIP_PROBETIMEOUT", To: "CAMUNDA_CLUSTER_MEMBERSHIP_PROBETIMEOUT", Note: "both set with different values, kept CAMUNDA_CLUSTER_MEMBERSHIP_PROBETIMEOUT"},
+		{Action: EnvDropped, From: "ZEEBE_BROKER_DATA_SNAPSHOTPERIOD", To: "CAMUNDA_DATA_SNAPSHOTPERIOD", Note: "already set"},
+		{Action: EnvUnmapped, From: "ZEEBE_BROKER_GATEWAY_ENABLE", Note: "deprecated ZEEBE_* entry without known equivalent, review manually"},
+	}, report.Changes)
+	require.Contains(t, report.String(), "renamed  ZEEBE_BROKER_CLUSTER_INITIALCONTACTPOINTS -> CAMUNDA_CLUSTER_INITIALCONTACTPOINTS")
+
+	var values struct {
+		Orchestration struct {
+			ClusterSize string                   `yaml:"clusterSize"`
+			Env         []map[string]interface{} `yaml:"env"`
+		} `yaml:"orchestration"`
+	}
+	require.NoError(t, yaml.Unmarshal(overlay, &values))
+	require.Empty(t, values.Orchestration.ClusterSize, "the overlay only holds the env")
+
+	var names []string
+	for _, entry := range values.Orchestration.Env {
+		names = append(names, entry["name"].(string))
+	}
+	require.Equal(t, []string{
+		"CAMUNDA_CLUSTER_INITIALCONTACTPOINTS",
+		"ZEEBE_BROKER_EXPORTERS_CAMUNDAREGION0_CLASSNAME",
+		"CAMUNDA_CLUSTER_MEMBERSHIP_PROBETIMEOUT",
+		"CAMUNDA_DATA_SNAPSHOTPERIOD",
+		"ZEEBE_BROKER_GATEWAY_ENABLE",
+		"JAVA_TOOL_OPTIONS",
+	}, names)
+	require.Equal(t, "a:26502,b:26502", values.Orchestration.Env[0]["value"])
+	require.NotNil(t, values.Orchestration.Env[5]["valueFrom"])
+}
+
+func TestMigrateEnvOfCurrentValues(t *testing.T) {
+	overlay, report, err := MigrateEnvFile(exampleValues)
+	require.NoError(t, err)
+	require.Nil(t, overlay)
+	require.False(t, report.Changed())
+	require.False(t, report.NeedsReview())
+}
+
+func TestEnvRenamesAreUnique(t *testing.T) {
+	from, to := map[string]bool{}, map[string]bool{}
+	for _, rename := range EnvRenames {
+		require.False(t, from[rename.From], "%s is mapped twice", rename.From)
+		require.False(t, to[rename.To], "%s is the target of two entries", rename.To)
+		from[rename.From], to[rename.To] = true, true
+	}
+}
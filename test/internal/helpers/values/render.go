@@ -0,0 +1,20 @@
+package valuesHelpers
+
+import "strings"
+
+// Placeholder marks values in the example files the tests fill in
+const Placeholder = "PLACEHOLDER"
+
+// ExampleNamespaces are the namespaces of the example values files, replaced by the namespaces of the regions
+var ExampleNamespaces = []string{"camunda-primary", "camunda-secondary"}
+
+// ExampleNamespaceReferences returns the example namespaces still referenced by content, e.g. by env entries the overlay doesn't set
+func ExampleNamespaceReferences(content string) []string {
+	var found []string
+	for _, namespace := range ExampleNamespaces {
+		if strings.Contains(content, "."+namespace+".") {
+			found = append(found, namespace)
+		}
+	}
+	return found
+}
@@ -0,0 +1,85 @@
+package valuesHelpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestOverlayMergesEnvByName(t *testing.T) {
+	values := NewBuilder()
+	require.NoError(t, values.Load([]byte(`orchestration:
+  clusterSize: '8'
+  env:
+    - name: CAMUNDA_DATA_BACKUP_REPOSITORYNAME
+      value: camunda_backup
+    - name: CAMUNDA_CLUSTER_INITIALCONTACTPOINTS
+      value: PLACEHOLDER
+    - name: CAMUNDA_DATA_SNAPSHOTPERIOD
+      value: 5m
+`)))
+
+	config := Config{Release: "camunda", Namespaces: []string{"a", "b"}, ClusterSize: 4}
+	overlay, err := config.Overlay(values, 1)
+	require.NoError(t, err)
+	require.Equal(t, MultiregionValues{Regions: 2, RegionId: 1}, overlay.Global.Multiregion)
+	require.Equal(t, "4", overlay.Orchestration.ClusterSize)
+
+	var names []string
+	for _, env := range overlay.Orchestration.Env {
+		names = append(names, env.Name)
+	}
+	require.Equal(t, []string{
+		"CAMUNDA_DATA_BACKUP_REPOSITORYNAME",
+		InitialContactPointsEnv,
+		"CAMUNDA_DATA_SNAPSHOTPERIOD",
+		ExporterClassNameEnv(0), ExporterConnectURLEnv(0),
+		ExporterClassNameEnv(1), ExporterConnectURLEnv(1),
+	}, names)
+	require.Equal(t, config.InitialContactPoints(), overlay.Orchestration.Env[1].Value)
+	require.Equal(t, "5m", overlay.Orchestration.Env[2].Value)
+
+	_, err = config.Overlay(values, 2)
+	require.ErrorContains(t, err, "not one of the 2 regions")
+}
+
+func TestApplyOverlayReplacesEnvAsAWhole(t *testing.T) {
+	values := NewBuilder()
+	require.NoError(t, values.LoadFiles(exampleValues))
+	before, err := values.Env()
+	require.NoError(t, err)
+
+	config := Config{Release: "camunda", Namespaces: []string{"c8-cluster-0", "c8-cluster-1"}, ClusterSize: 8}
+	content, err := config.ApplyOverlay(values, 0)
+	require.NoError(t, err)
+
+	// Helm replaces the env of the values files with the one of the overlay, which has to keep every entry
+	var overlay Overlay
+	require.NoError(t, yaml.Unmarshal(content, &overlay))
+	require.Len(t, overlay.Orchestration.Env, len(before))
+
+	rendered, err := values.Render()
+	require.NoError(t, err)
+	require.NotContains(t, string(rendered), Placeholder)
+	require.Empty(t, ExampleNamespaceReferences(string(rendered)))
+}
+
+func TestOverlayOfMigration(t *testing.T) {
+	values := NewBuilder()
+	require.NoError(t, values.LoadFiles("../../../../aws/dual-region/kubernetes/camunda-values-migration.yml"))
+	rendered, err := values.Render()
+	require.NoError(t, err)
+	require.NotEmpty(t, ExampleNamespaceReferences(string(rendered)))
+
+	config := Config{Release: "camunda", Namespaces: []string{"c8-cluster-0", "c8-cluster-1"}, ClusterSize: 8}
+	_, err = config.ApplyOverlay(values, 1)
+	require.NoError(t, err)
+
+	rendered, err = values.Render()
+	require.NoError(t, err)
+	require.Empty(t, ExampleNamespaceReferences(string(rendered)))
+	env, err := values.Env()
+	require.NoError(t, err)
+	require.Contains(t, env, EnvVar{Name: ElasticsearchExporterURLEnv(1), Value: config.ElasticsearchURL(1)})
+}
@@ -0,0 +1,230 @@
+package valuesHelpers
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Ports the generated addresses point to
+const (
+	ClusterPort       = 26502
+	ElasticsearchPort = 9200
+)
+
+// MinClusterSize is the smallest cluster size supported in a multi-region setup
+const MinClusterSize = 4
+
+// Env entries generated for a multi-region installation
+const (
+	InitialContactPointsEnv = "CAMUNDA_CLUSTER_INITIALCONTACTPOINTS"
+	CamundaExporterClass    = "io.camunda.exporter.CamundaExporter"
+)
+
+// Config describes a multi-region installation, the same inputs generate_zeebe_helm_values.sh asks for
+type Config struct {
+	Release string
+	// Namespaces holds the Camunda namespace of every region, indexed by region id
+	Namespaces  []string
+	ClusterSize int
+	// ContactPointsEnv and ExporterClass depend on the Camunda version, InitialContactPointsEnv and CamundaExporterClass if empty
+	ContactPointsEnv string
+	ExporterClass    string
+}
+
+// EnvVar is an entry of orchestration.env
+type EnvVar struct {
+	Name      string                 `yaml:"name"`
+	Value     string                 `yaml:"value"`
+	ValueFrom map[string]interface{} `yaml:"valueFrom,omitempty"`
+}
+
+// Overlay is the part of the Helm values that depends on the regions, the cluster size and the release
+type Overlay struct {
+	Global        GlobalValues        `yaml:"global"`
+	Orchestration OrchestrationValues `yaml:"orchestration"`
+}
+
+// GlobalValues of the overlay
+type GlobalValues struct {
+	Multiregion MultiregionValues `yaml:"multiregion"`
+}
+
+// MultiregionValues of the overlay
+type MultiregionValues struct {
+	Regions  int `yaml:"regions"`
+	RegionId int `yaml:"regionId"`
+}
+
+// OrchestrationValues of the overlay, the chart expects the cluster size as string
+type OrchestrationValues struct {
+	ClusterSize string   `yaml:"clusterSize"`
+	Env         []EnvVar `yaml:"env"`
+}
+
+// Regions returns the number of regions
+func (c Config) Regions() int {
+	return len(c.Namespaces)
+}
+
+// Validate applies the checks of generate_zeebe_helm_values.sh, generalized to any number of regions
+func (c Config) Validate() error {
+	if c.Release == "" {
+		return fmt.Errorf("a Helm release name is required")
+	}
+	if c.Regions() < 2 {
+		return fmt.Errorf("a multi-region setup needs at least 2 regions, got %d", c.Regions())
+	}
+	if c.ClusterSize%c.Regions() != 0 {
+		return fmt.Errorf("cluster size %d can't be split evenly across %d regions", c.ClusterSize, c.Regions())
+	}
+	if c.ClusterSize < MinClusterSize {
+		return fmt.Errorf("cluster size %d is too small and should be at least %d, a multi-region setup is not recommended for a small cluster size", c.ClusterSize, MinClusterSize)
+	}
+
+	seen := map[string]bool{}
+	for region, namespace := range c.Namespaces {
+		if namespace == "" {
+			return fmt.Errorf("region %d has no namespace", region)
+		}
+		if seen[namespace] {
+			return fmt.Errorf("namespace %s is used by more than one region, Camunda installations must be called differently", namespace)
+		}
+		seen[namespace] = true
+	}
+	return nil
+}
+
+// InitialContactPoints lists the brokers of all regions ordered by pod index, the value of CAMUNDA_CLUSTER_INITIALCONTACTPOINTS
+func (c Config) InitialContactPoints() string {
+	var contactPoints []string
+	for podIndex := 0; podIndex < c.ClusterSize/c.Regions(); podIndex++ {
+		for _, namespace := range c.Namespaces {
+			contactPoints = append(contactPoints, fmt.Sprintf("%s-zeebe-%d.%s-zeebe.%s.svc.cluster.local:%d", c.Release, podIndex, c.Release, namespace, ClusterPort))
+		}
+	}
+	return strings.Join(contactPoints, ",")
+}
+
+// ElasticsearchURL returns the URL the exporter of a region uses to reach its Elasticsearch
+func (c Config) ElasticsearchURL(region int) string {
+	return fmt.Sprintf("http://%s-elasticsearch-master-hl.%s.svc.cluster.local:%d", c.Release, c.Namespaces[region], ElasticsearchPort)
+}
+
+// ExporterClassNameEnv returns the environment variable configuring the class of the exporter of a region
+func ExporterClassNameEnv(region int) string {
+	return fmt.Sprintf("ZEEBE_BROKER_EXPORTERS_CAMUNDAREGION%d_CLASSNAME", region)
+}
+
+// ExporterConnectURLEnv returns the environment variable configuring the Elasticsearch URL of the exporter of a region
+func ExporterConnectURLEnv(region int) string {
+	return fmt.Sprintf("ZEEBE_BROKER_EXPORTERS_CAMUNDAREGION%d_ARGS_CONNECT_URL", region)
+}
+
+// ElasticsearchExporterURLEnv returns the environment variable configuring the URL of the Elasticsearch exporter of a region, used by migrations
+func ElasticsearchExporterURLEnv(region int) string {
+	return fmt.Sprintf("ZEEBE_BROKER_EXPORTERS_ELASTICSEARCHREGION%d_ARGS_URL", region)
+}
+
+// Env returns the generated env entries, the initial contact points followed by the exporter of every region
+func (c Config) Env() []EnvVar {
+	contactPointsEnv, exporterClass := c.ContactPointsEnv, c.ExporterClass
+	if contactPointsEnv == "" {
+		contactPointsEnv = InitialContactPointsEnv
+	}
+	if exporterClass == "" {
+		exporterClass = CamundaExporterClass
+	}
+
+	env := []EnvVar{{Name: contactPointsEnv, Value: c.InitialContactPoints()}}
+	for region := range c.Namespaces {
+		env = append(env,
+			EnvVar{Name: ExporterClassNameEnv(region), Value: exporterClass},
+			EnvVar{Name: ExporterConnectURLEnv(region), Value: c.ElasticsearchURL(region)},
+		)
+	}
+	return env
+}
+
+// Overlay returns the values overlay of a region, passed to Helm after the values files merged into values
+// Its env is the env of values with the generated entries merged in by name
+// The Elasticsearch exporter URLs of a migration are only set if values declares them
+func (c Config) Overlay(values *Builder, regionId int) (Overlay, error) {
+	if err := c.Validate(); err != nil {
+		return Overlay{}, err
+	}
+	if regionId < 0 || regionId >= c.Regions() {
+		return Overlay{}, fmt.Errorf("region id %d is not one of the %d regions", regionId, c.Regions())
+	}
+
+	declared, err := values.Env()
+	if err != nil {
+		return Overlay{}, err
+	}
+	generated := c.Env()
+	for region := range c.Namespaces {
+		name := ElasticsearchExporterURLEnv(region)
+		if slices.ContainsFunc(declared, func(env EnvVar) bool { return env.Name == name }) {
+			generated = append(generated, EnvVar{Name: name, Value: c.ElasticsearchURL(region)})
+		}
+	}
+
+	merged := NewBuilder()
+	if err := merged.SetEnv(declared...); err != nil {
+		return Overlay{}, err
+	}
+	if err := merged.SetEnv(generated...); err != nil {
+		return Overlay{}, err
+	}
+	env, err := merged.Env()
+	if err != nil {
+		return Overlay{}, err
+	}
+
+	return Overlay{
+		Global: GlobalValues{Multiregion: MultiregionValues{Regions: c.Regions(), RegionId: regionId}},
+		Orchestration: OrchestrationValues{
+			ClusterSize: strconv.Itoa(c.ClusterSize),
+			Env:         env,
+		},
+	}, nil
+}
+
+// ApplyOverlay merges the overlay of a region into values like Helm does with a values file passed last, and returns that file
+func (c Config) ApplyOverlay(values *Builder, regionId int) ([]byte, error) {
+	overlay, err := c.Overlay(values, regionId)
+	if err != nil {
+		return nil, err
+	}
+	content, err := overlay.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return content, values.Load(content)
+}
+
+// Marshal renders the overlay as a values file
+func (o Overlay) Marshal() ([]byte, error) {
+	return yaml.Marshal(o)
+}
+
+// ValuesRegionId returns global.multiregion.regionId of a values file, ok is false if the file does not set it
+func ValuesRegionId(content []byte) (regionId int, ok bool, err error) {
+	var values struct {
+		Global struct {
+			Multiregion struct {
+				RegionId *int `yaml:"regionId"`
+			} `yaml:"multiregion"`
+		} `yaml:"global"`
+	}
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return 0, false, fmt.Errorf("failed to parse values: %w", err)
+	}
+	if values.Global.Multiregion.RegionId == nil {
+		return 0, false, nil
+	}
+	return *values.Global.Multiregion.RegionId, true, nil
+}
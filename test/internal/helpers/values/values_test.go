@@ -0,0 +1,72 @@
+package valuesHelpers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitialContactPointsMatchScript(t *testing.T) {
+	config := Config{Release: "camunda", Namespaces: []string{"c8-snap-cluster-0", "c8-snap-cluster-1"}, ClusterSize: 8}
+	require.NoError(t, config.Validate())
+
+	// As printed by generate_zeebe_helm_values.sh and shipped in camunda-values.yml
+	require.Equal(t, "camunda-zeebe-0.camunda-zeebe.c8-snap-cluster-0.svc.cluster.local:26502,camunda-zeebe-0.camunda-zeebe.c8-snap-cluster-1.svc.cluster.local:26502,"+
+		"camunda-zeebe-1.camunda-zeebe.c8-snap-cluster-0.svc.cluster.local:26502,camunda-zeebe-1.camunda-zeebe.c8-snap-cluster-1.svc.cluster.local:26502,"+
+		"camunda-zeebe-2.camunda-zeebe.c8-snap-cluster-0.svc.cluster.local:26502,camunda-zeebe-2.camunda-zeebe.c8-snap-cluster-1.svc.cluster.local:26502,"+
+		"camunda-zeebe-3.camunda-zeebe.c8-snap-cluster-0.svc.cluster.local:26502,camunda-zeebe-3.camunda-zeebe.c8-snap-cluster-1.svc.cluster.local:26502",
+		config.InitialContactPoints())
+	require.Equal(t, "http://camunda-elasticsearch-master-hl.c8-snap-cluster-1.svc.cluster.local:9200", config.ElasticsearchURL(1))
+}
+
+func TestInitialContactPointsOfThreeRegions(t *testing.T) {
+	config := Config{Release: "c8", Namespaces: []string{"a", "b", "c"}, ClusterSize: 6}
+	require.NoError(t, config.Validate())
+	require.Equal(t, []string{
+		"c8-zeebe-0.c8-zeebe.a.svc.cluster.local:26502",
+		"c8-zeebe-0.c8-zeebe.b.svc.cluster.local:26502",
+		"c8-zeebe-0.c8-zeebe.c.svc.cluster.local:26502",
+		"c8-zeebe-1.c8-zeebe.a.svc.cluster.local:26502",
+		"c8-zeebe-1.c8-zeebe.b.svc.cluster.local:26502",
+		"c8-zeebe-1.c8-zeebe.c.svc.cluster.local:26502",
+	}, strings.Split(config.InitialContactPoints(), ","))
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config Config
+		err    string
+	}{
+		{"odd size", Config{Release: "camunda", Namespaces: []string{"a", "b"}, ClusterSize: 7}, "split evenly"},
+		{"too small", Config{Release: "camunda", Namespaces: []string{"a", "b"}, ClusterSize: 2}, "at least 4"},
+		{"same namespace", Config{Release: "camunda", Namespaces: []string{"a", "a"}, ClusterSize: 8}, "called differently"},
+		{"single region", Config{Release: "camunda", Namespaces: []string{"a"}, ClusterSize: 8}, "at least 2 regions"},
+		{"empty namespace", Config{Release: "camunda", Namespaces: []string{"a", ""}, ClusterSize: 8}, "region 1 has no namespace"},
+		{"no release", Config{Namespaces: []string{"a", "b"}, ClusterSize: 8}, "release name"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.ErrorContains(t, tc.config.Validate(), tc.err)
+		})
+	}
+}
+
+func TestValuesRegionId(t *testing.T) {
+	regionId, ok, err := ValuesRegionId([]byte("---\nglobal:\n    multiregion:\n        regionId: 1\n"))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 1, regionId)
+
+	_, ok, err = ValuesRegionId([]byte("global:\n    multiregion:\n        regions: 2\n"))
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestEnvOfOlderVersions(t *testing.T) {
+	config := Config{Release: "camunda", Namespaces: []string{"a", "b"}, ClusterSize: 4,
+		ContactPointsEnv: "ZEEBE_BROKER_CLUSTER_INITIALCONTACTPOINTS", ExporterClass: "io.camunda.zeebe.exporter.ElasticsearchExporter"}
+	env := config.Env()
+	require.Equal(t, "ZEEBE_BROKER_CLUSTER_INITIALCONTACTPOINTS", env[0].Name)
+	require.Equal(t, EnvVar{Name: ExporterClassNameEnv(1), Value: "io.camunda.zeebe.exporter.ElasticsearchExporter"}, env[3])
+}
@@ -0,0 +1,160 @@
+package versionHelpers
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Capability is a feature the tests depend on that only some Camunda versions have
+type Capability string
+
+const (
+	// ActuatorCluster is the cluster API of the gateway, /actuator/cluster, used for scaling and force removal of brokers
+	ActuatorCluster Capability = "actuator-cluster"
+	// ActuatorExporters is enabling and disabling exporters through /actuator/exporters, used by the failover procedure
+	ActuatorExporters Capability = "actuator-exporters"
+	// CamundaEnv is the CAMUNDA_* naming of the unified configuration, older versions are configured through ZEEBE_*
+	CamundaEnv Capability = "camunda-env"
+	// CamundaExporter is the Camunda exporter writing to the secondary storage, older versions only have the Elasticsearch exporter
+	CamundaExporter Capability = "camunda-exporter"
+	// Migration is migrating a pre 8.8 installation with Elasticsearch exporters to the Camunda exporter
+	Migration Capability = "migration"
+	// OrchestrationValues is the orchestration section of the chart values, older charts have a zeebe section
+	OrchestrationValues Capability = "orchestration-values"
+)
+
+// Exporter classes of the region specific exporters
+const (
+	CamundaExporterClass       = "io.camunda.exporter.CamundaExporter"
+	ElasticsearchExporterClass = "io.camunda.zeebe.exporter.ElasticsearchExporter"
+)
+
+// Snapshot chart versions, the alpha chart ships the next minor version, the latest snapshot the current one
+// With the registry below 0.0.0-snapshot-alpha is 8.9 (no Migration) and 0.0.0-snapshot-latest is 8.8
+const (
+	SnapshotAlphaChart  = "0.0.0-snapshot-alpha"
+	SnapshotLatestChart = "0.0.0-snapshot-latest"
+)
+
+// Release is a minor release of Camunda and the major version of the chart shipping it
+type Release struct {
+	App          string
+	ChartMajor   int
+	Capabilities []Capability
+}
+
+// registry lists the supported releases, oldest first
+var registry = []Release{
+	{App: "8.5", ChartMajor: 10},
+	{App: "8.6", ChartMajor: 11, Capabilities: []Capability{ActuatorCluster, ActuatorExporters}},
+	{App: "8.7", ChartMajor: 12, Capabilities: []Capability{ActuatorCluster, ActuatorExporters}},
+	{App: "8.8", ChartMajor: 13, Capabilities: []Capability{ActuatorCluster, ActuatorExporters, CamundaEnv, CamundaExporter, Migration, OrchestrationValues}},
+	{App: "8.9", ChartMajor: 14, Capabilities: []Capability{ActuatorCluster, ActuatorExporters, CamundaEnv, CamundaExporter, OrchestrationValues}},
+}
+
+// Capabilities describes what the Camunda version installed by a chart version supports
+type Capabilities struct {
+	Release
+	ChartVersion string
+	// Published is false for snapshot charts, which are not available from the Helm repository
+	Published bool
+}
+
+// ForChart looks up the capabilities of a chart version
+// Chart majors newer than the registry fall back to the newest release, older unknown ones are an error
+func ForChart(chartVersion string) (Capabilities, error) {
+	switch chartVersion {
+	case SnapshotAlphaChart:
+		return Capabilities{Release: registry[len(registry)-1], ChartVersion: chartVersion}, nil
+	case SnapshotLatestChart:
+		return Capabilities{Release: registry[len(registry)-2], ChartVersion: chartVersion}, nil
+	}
+
+	major, err := strconv.Atoi(strings.SplitN(strings.TrimPrefix(chartVersion, "v"), ".", 2)[0])
+	if err != nil {
+		return Capabilities{}, fmt.Errorf("chart version %q is not a version", chartVersion)
+	}
+	published := !strings.Contains(chartVersion, "snapshot")
+	for _, release := range registry {
+		if release.ChartMajor == major {
+			return Capabilities{Release: release, ChartVersion: chartVersion, Published: published}, nil
+		}
+	}
+
+	newest := registry[len(registry)-1]
+	if major > newest.ChartMajor {
+		log.Printf("[VERSION] Chart version %s is newer than the registry, assuming the capabilities of Camunda %s (chart %d)", chartVersion, newest.App, newest.ChartMajor)
+		return Capabilities{Release: newest, ChartVersion: chartVersion, Published: published}, nil
+	}
+	return Capabilities{}, fmt.Errorf("chart version %s is not in the registry", chartVersion)
+}
+
+// ForApp looks up the release of a Camunda version like 8.8.3, e.g. to check a GLOBAL_IMAGE_TAG
+func ForApp(appVersion string) (Release, error) {
+	parts := strings.Split(appVersion, ".")
+	if len(parts) >= 2 {
+		minor := parts[0] + "." + parts[1]
+		for _, release := range registry {
+			if release.App == minor {
+				return release, nil
+			}
+		}
+	}
+	return Release{}, fmt.Errorf("version %q of Camunda is not in the registry", appVersion)
+}
+
+// Supports reports whether the release has the capability
+func (r Release) Supports(capability Capability) bool {
+	for _, c := range r.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// Require skips the test if the version does not have the capability
+func (c Capabilities) Require(t *testing.T, capabilities ...Capability) {
+	t.Helper()
+
+	for _, capability := range capabilities {
+		if !c.Supports(capability) {
+			t.Skipf("[VERSION] Skipping, %s is not available in Camunda %s installed by chart %s", capability, c.App, c.ChartVersion)
+		}
+	}
+}
+
+// EnvPrefix returns the prefix of the cluster configuration env entries
+func (r Release) EnvPrefix() string {
+	if r.Supports(CamundaEnv) {
+		return "CAMUNDA"
+	}
+	return "ZEEBE"
+}
+
+// InitialContactPointsEnv returns the env entry holding the initial contact points of the brokers
+func (r Release) InitialContactPointsEnv() string {
+	if r.Supports(CamundaEnv) {
+		return "CAMUNDA_CLUSTER_INITIALCONTACTPOINTS"
+	}
+	return "ZEEBE_BROKER_CLUSTER_INITIALCONTACTPOINTS"
+}
+
+// ExporterClass returns the class of the region specific exporters
+func (r Release) ExporterClass() string {
+	if r.Supports(CamundaExporter) {
+		return CamundaExporterClass
+	}
+	return ElasticsearchExporterClass
+}
+
+// ProfileKey returns the values key enabling a web application like operate or tasklist
+func (r Release) ProfileKey(component string) string {
+	if r.Supports(OrchestrationValues) {
+		return fmt.Sprintf("orchestration.profiles.%s", component)
+	}
+	return fmt.Sprintf("%s.enabled", component)
+}
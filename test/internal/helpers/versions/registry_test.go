@@ -0,0 +1,68 @@
+package versionHelpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestForChart(t *testing.T) {
+	chart, err := ForChart("13.4.2")
+	require.NoError(t, err)
+	require.Equal(t, "8.8", chart.App)
+	require.True(t, chart.Published)
+	require.True(t, chart.Supports(Migration))
+	require.Equal(t, "orchestration.profiles.operate", chart.ProfileKey("operate"))
+	require.Equal(t, "CAMUNDA_CLUSTER_INITIALCONTACTPOINTS", chart.InitialContactPointsEnv())
+	require.Equal(t, CamundaExporterClass, chart.ExporterClass())
+
+	chart, err = ForChart("11.2.0")
+	require.NoError(t, err)
+	require.Equal(t, "8.6", chart.App)
+	require.True(t, chart.Supports(ActuatorExporters))
+	require.False(t, chart.Supports(OrchestrationValues))
+	require.Equal(t, "operate.enabled", chart.ProfileKey("operate"))
+	require.Equal(t, "ZEEBE", chart.EnvPrefix())
+	require.Equal(t, ElasticsearchExporterClass, chart.ExporterClass())
+
+	chart, err = ForChart("99.0.0")
+	require.NoError(t, err)
+	require.Equal(t, "8.9", chart.App)
+	require.True(t, chart.Published)
+	_, err = ForChart("9.0.0")
+	require.ErrorContains(t, err, "not in the registry")
+	_, err = ForChart("latest")
+	require.ErrorContains(t, err, "not a version")
+}
+
+func TestForSnapshotCharts(t *testing.T) {
+	alpha, err := ForChart(SnapshotAlphaChart)
+	require.NoError(t, err)
+	require.Equal(t, "8.9", alpha.App)
+	require.False(t, alpha.Published)
+
+	latest, err := ForChart(SnapshotLatestChart)
+	require.NoError(t, err)
+	require.Equal(t, "8.8", latest.App)
+	require.False(t, latest.Published)
+}
+
+func TestForApp(t *testing.T) {
+	release, err := ForApp("8.7.12")
+	require.NoError(t, err)
+	require.Equal(t, 12, release.ChartMajor)
+
+	_, err = ForApp("SNAPSHOT")
+	require.Error(t, err)
+}
+
+func TestRequireSkips(t *testing.T) {
+	chart, err := ForChart("10.0.0")
+	require.NoError(t, err)
+
+	skipped := t.Run("scaling", func(t *testing.T) {
+		chart.Require(t, ActuatorCluster)
+		t.Fatal("not skipped")
+	})
+	require.True(t, skipped)
+}
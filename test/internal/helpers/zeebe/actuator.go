@@ -0,0 +1,127 @@
+package zeebeHelpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	kubectlHelpers "multiregiontests/internal/helpers/kubectl"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+)
+
+// ManagementPort is the Zeebe gateway port serving the actuator endpoints
+const ManagementPort = 9600
+
+// ActuatorError is returned whenever the actuator answers with a 4xx or 5xx status code
+type ActuatorError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *ActuatorError) Error() string {
+	return fmt.Sprintf("actuator %s %s returned %d: %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+// IsClientError reports whether err is an ActuatorError with a 4xx status code
+func IsClientError(err error) bool {
+	var actuatorErr *ActuatorError
+	return errors.As(err, &actuatorErr) && actuatorErr.StatusCode >= 400 && actuatorErr.StatusCode < 500
+}
+
+// IsServerError reports whether err is an ActuatorError with a 5xx status code
+func IsServerError(err error) bool {
+	var actuatorErr *ActuatorError
+	return errors.As(err, &actuatorErr) && actuatorErr.StatusCode >= 500
+}
+
+// ActuatorClient talks to the management API of a Zeebe gateway
+type ActuatorClient struct {
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+// NewActuatorClient creates a client for the given host:port endpoint
+func NewActuatorClient(endpoint string) *ActuatorClient {
+	return &ActuatorClient{
+		Endpoint:   endpoint,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// NewActuatorTunnel opens a port-forward to the management port of camunda-zeebe-gateway and returns a client using it
+func NewActuatorTunnel(t *testing.T, kubectlOptions *k8s.KubectlOptions) (*ActuatorClient, func()) {
+	t.Helper()
+
+	endpoint, closeFn := kubectlHelpers.NewServiceTunnelWithRetry(t, kubectlOptions, "camunda-zeebe-gateway", 0, ManagementPort, 5, 15*time.Second)
+	return NewActuatorClient(endpoint), closeFn
+}
+
+// Do sends an arbitrary request to the management API, for endpoints without a typed method
+// Forced requests are refused, they have to go through PatchCluster with a ForceRemovalGate
+func (c *ActuatorClient) Do(method, path string, query url.Values, payload, out interface{}) error {
+	if force, _ := strconv.ParseBool(query.Get("force")); force {
+		return fmt.Errorf("refusing %s %s with force=true without a force removal gate", method, path)
+	}
+	return c.do(method, path, query, payload, out)
+}
+
+// do sends the request and decodes a JSON response into out, if out is not nil
+func (c *ActuatorClient) do(method, path string, query url.Values, payload, out interface{}) error {
+	target := fmt.Sprintf("http://%s%s", c.Endpoint, path)
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if payload != nil {
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal payload for %s %s: %w", method, path, err)
+		}
+		body = bytes.NewBuffer(payloadBytes)
+	}
+
+	req, err := http.NewRequest(method, target, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request %s %s: %w", method, path, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request %s %s: %w", method, path, err)
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response of %s %s: %w", method, path, err)
+	}
+
+	if res.StatusCode >= 400 {
+		return &ActuatorError{Method: method, Path: path, StatusCode: res.StatusCode, Body: string(resBody)}
+	}
+
+	if out == nil || len(resBody) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(resBody, out); err != nil {
+		return fmt.Errorf("failed to decode response of %s %s: %w (body: %s)", method, path, err, string(resBody))
+	}
+
+	return nil
+}
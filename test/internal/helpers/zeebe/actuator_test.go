@@ -0,0 +1,100 @@
+package zeebeHelpers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const clusterResponse = `{
+  "version": 7,
+  "brokers": [
+    {"id": 1, "state": "ACTIVE", "version": 2, "partitions": [{"id": 1, "state": "ACTIVE", "priority": 1}]},
+    {"id": 0, "state": "ACTIVE", "version": 2, "partitions": [{"id": 1, "state": "ACTIVE", "priority": 2, "config": {"exporting": {"exporters": [{"id": "camundaregion0", "state": "ENABLED"}]}}}]}
+  ],
+  "lastChange": {"id": 6, "status": "COMPLETED", "startedAt": "2024-01-01T00:00:00Z", "completedAt": "2024-01-01T00:01:00Z"},
+  "pendingChange": {"id": 7, "status": "IN_PROGRESS", "completed": [{"operation": "BROKER_ADD", "brokerId": 2}], "pending": [{"operation": "PARTITION_JOIN", "brokerId": 2, "partitionId": 1, "priority": 1}]}
+}`
+
+func TestGetClusterDecodesTopology(t *testing.T) {
+	client := newActuatorTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "/actuator/cluster", r.URL.Path)
+		io.WriteString(w, clusterResponse)
+	})
+
+	topology, err := client.GetCluster()
+	require.NoError(t, err)
+
+	require.Equal(t, []int{0, 1}, topology.BrokerIds())
+	require.True(t, topology.HasPendingChange())
+	require.Equal(t, ChangeStatusCompleted, topology.LastChange.Status)
+	require.Equal(t, "camundaregion0", topology.Broker(0).Partitions[0].Config.Exporting.Exporters[0].Id)
+
+	completed, total := topology.PendingChange.Progress()
+	require.Equal(t, 1, completed)
+	require.Equal(t, 2, total)
+}
+
+func TestPatchClusterSendsTypedPayload(t *testing.T) {
+	safe := gate()
+	client := newActuatorTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPatch, r.Method)
+		require.Equal(t, "true", r.URL.Query().Get("force"))
+		require.Empty(t, r.URL.Query().Get("dryRun"))
+
+		var change map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&change))
+		require.Equal(t, map[string]interface{}{"brokers": map[string]interface{}{"remove": []interface{}{1.0, 3.0}}}, change)
+
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, `{"changeId": 8, "plannedChanges": [{"operation": "PARTITION_FORCE_RECONFIGURE", "brokerId": 0, "partitionId": 1, "brokers": [0]}], "expectedTopology": [{"id": 0, "state": "ACTIVE"}]}`)
+	})
+
+	response, err := client.PatchCluster(ClusterChangeRequest{Brokers: &BrokersChange{Remove: []int{1, 3}}}, PatchOptions{Force: &safe})
+	require.NoError(t, err)
+	require.Equal(t, int64(8), response.ChangeId)
+	require.True(t, response.HasOperation(OperationPartitionForceReconfigure))
+	require.NotNil(t, response.ExpectedBroker(0))
+	require.Nil(t, response.ExpectedBroker(1))
+}
+
+func TestForcedRequestsNeedAGate(t *testing.T) {
+	client := newActuatorTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("unexpected %s %s", r.Method, r.URL)
+	})
+
+	unsafe := gate()
+	unsafe.StatefulSetErr = nil
+	unsafe.StatefulSetReplicas = 4
+	_, err := client.PatchCluster(ClusterChangeRequest{Brokers: &BrokersChange{Remove: []int{1, 3}}}, PatchOptions{Force: &unsafe})
+	require.ErrorContains(t, err, "refusing to force")
+
+	for _, force := range []string{"true", "True", "1"} {
+		err = client.Do(http.MethodPatch, "/actuator/cluster", url.Values{"force": {force}}, ClusterChangeRequest{}, nil)
+		require.ErrorContains(t, err, "without a force removal gate")
+	}
+}
+
+func TestActuatorErrorsAreClassified(t *testing.T) {
+	status := http.StatusBadRequest
+	client := newActuatorTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		io.WriteString(w, `{"message": "nope"}`)
+	})
+
+	_, err := client.GetCluster()
+	require.Error(t, err)
+	require.True(t, IsClientError(err))
+	require.False(t, IsServerError(err))
+
+	status = http.StatusInternalServerError
+	_, err = client.GetCluster()
+	require.Error(t, err)
+	require.False(t, IsClientError(err))
+	require.True(t, IsServerError(err))
+}
@@ -0,0 +1,119 @@
+package zeebeHelpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ChangeFailedError is returned when a tracked change ended in FAILED or CANCELLED state
+type ChangeFailedError struct {
+	ChangeId int64
+	Status   string
+	Pending  []Operation
+}
+
+func (e *ChangeFailedError) Error() string {
+	msg := fmt.Sprintf("cluster change %d ended with status %s", e.ChangeId, e.Status)
+	if len(e.Pending) > 0 {
+		ops := make([]string, 0, len(e.Pending))
+		for _, op := range e.Pending {
+			ops = append(ops, describeOperation(op, true))
+		}
+		msg += fmt.Sprintf(", operations not executed: [%s]", strings.Join(ops, "; "))
+	}
+	return msg
+}
+
+// ChangeReplacedError is returned when a different change shows up while the tracked one was expected
+type ChangeReplacedError struct {
+	ChangeId      int64
+	ReplacedById  int64
+	ReplacedState string
+}
+
+func (e *ChangeReplacedError) Error() string {
+	return fmt.Sprintf("cluster change %d was replaced by change %d (%s)", e.ChangeId, e.ReplacedById, e.ReplacedState)
+}
+
+// ChangeProgressFunc is called on every poll while the tracked change is still in progress
+type ChangeProgressFunc func(attempt int, change TopologyChange)
+
+// ChangeState determines the state of the change with the given id from the topology
+// done is true once the change reached a terminal state, err is set if that state is not COMPLETED
+// or if the change was replaced by another one
+func ChangeState(topology ClusterTopology, changeId int64) (done bool, err error) {
+	// A later pending change does not replace the tracked one once it is recorded as the last change
+	if last := topology.LastChange; last != nil && last.Id == changeId {
+		if last.Status != ChangeStatusCompleted {
+			return true, &ChangeFailedError{ChangeId: changeId, Status: last.Status}
+		}
+		return true, nil
+	}
+
+	if pending := topology.PendingChange; pending != nil {
+		switch {
+		case pending.Id == changeId && isFailedStatus(pending.Status):
+			return true, &ChangeFailedError{ChangeId: changeId, Status: pending.Status, Pending: pending.Pending}
+		case pending.Id == changeId:
+			return false, nil
+		case pending.Id > changeId:
+			return true, &ChangeReplacedError{ChangeId: changeId, ReplacedById: pending.Id, ReplacedState: pending.Status}
+		}
+	}
+
+	last := topology.LastChange
+	if last == nil || last.Id < changeId {
+		// the change was not picked up yet
+		return false, nil
+	}
+	return true, &ChangeReplacedError{ChangeId: changeId, ReplacedById: last.Id, ReplacedState: last.Status}
+}
+
+// WaitForChange follows the change with the given id until it reaches a terminal state
+// It fails fast if the change is FAILED, CANCELLED or replaced by another change
+func (c *ActuatorClient) WaitForChange(changeId int64, maxRetries int, interval time.Duration, onProgress ChangeProgressFunc) (ClusterTopology, error) {
+	var topology ClusterTopology
+	var err error
+
+	for i := 0; i < maxRetries; i++ {
+		topology, err = c.GetCluster()
+		if err == nil {
+			done, stateErr := ChangeState(topology, changeId)
+			if done {
+				return topology, stateErr
+			}
+			if onProgress != nil && topology.PendingChange != nil {
+				onProgress(i+1, *topology.PendingChange)
+			}
+		}
+		time.Sleep(interval)
+	}
+
+	if err != nil {
+		return topology, err
+	}
+	return topology, &PendingChangeTimeoutError{Change: topology.PendingChange}
+}
+
+// WaitForClusterChange waits for the change with the given id to complete and fails the test otherwise
+func WaitForClusterChange(t *testing.T, client *ActuatorClient, changeId int64, operationName string, maxRetries int, interval time.Duration) ClusterTopology {
+	t.Helper()
+	t.Logf("[CLUSTER CHANGE] Waiting for %s (change %d) to complete 🕐", operationName, changeId)
+
+	topology, err := client.WaitForChange(changeId, maxRetries, interval, func(attempt int, change TopologyChange) {
+		completed, total := change.Progress()
+		t.Logf("[CLUSTER CHANGE] %s in progress: %d/%d operations completed (attempt %d/%d)", operationName, completed, total, attempt, maxRetries)
+	})
+	require.NoError(t, err, "[CLUSTER CHANGE] %s did not complete", operationName)
+
+	t.Logf("[CLUSTER CHANGE] %s (change %d) completed successfully", operationName, changeId)
+	return topology
+}
+
+func isFailedStatus(status string) bool {
+	return status == ChangeStatusFailed || status == ChangeStatusCancelled
+}
@@ -0,0 +1,38 @@
+package zeebeHelpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeState(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		topology ClusterTopology
+		done     bool
+		err      interface{}
+	}{
+		{"not picked up yet", ClusterTopology{LastChange: &CompletedChange{Id: 4, Status: ChangeStatusCompleted}}, false, nil},
+		{"in progress", ClusterTopology{PendingChange: &TopologyChange{Id: 5, Status: ChangeStatusInProgress}}, false, nil},
+		{"completed", ClusterTopology{LastChange: &CompletedChange{Id: 5, Status: ChangeStatusCompleted}}, true, nil},
+		{"cancelled", ClusterTopology{LastChange: &CompletedChange{Id: 5, Status: ChangeStatusCancelled}}, true, &ChangeFailedError{}},
+		{"failed while pending", ClusterTopology{PendingChange: &TopologyChange{Id: 5, Status: ChangeStatusFailed}}, true, &ChangeFailedError{}},
+		{"replaced by pending", ClusterTopology{PendingChange: &TopologyChange{Id: 6, Status: ChangeStatusInProgress}}, true, &ChangeReplacedError{}},
+		{"completed before next pending", ClusterTopology{
+			LastChange:    &CompletedChange{Id: 5, Status: ChangeStatusCompleted},
+			PendingChange: &TopologyChange{Id: 6, Status: ChangeStatusInProgress},
+		}, true, nil},
+		{"replaced by last", ClusterTopology{LastChange: &CompletedChange{Id: 7, Status: ChangeStatusCompleted}}, true, &ChangeReplacedError{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			done, err := ChangeState(tc.topology, 5)
+			require.Equal(t, tc.done, done)
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.IsType(t, tc.err, err)
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package zeebeHelpers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+// Cluster change statuses as reported by /actuator/cluster
+const (
+	ChangeStatusInProgress = "IN_PROGRESS"
+	ChangeStatusCompleted  = "COMPLETED"
+	ChangeStatusFailed     = "FAILED"
+	ChangeStatusCancelled  = "CANCELLED"
+)
+
+// Broker states as reported by /actuator/cluster
+const (
+	BrokerStateActive  = "ACTIVE"
+	BrokerStateJoining = "JOINING"
+	BrokerStateLeaving = "LEAVING"
+	BrokerStateLeft    = "LEFT"
+)
+
+// Operation types that can be part of a planned or pending cluster change
+const (
+	OperationBrokerAdd                 = "BROKER_ADD"
+	OperationBrokerRemove              = "BROKER_REMOVE"
+	OperationPartitionJoin             = "PARTITION_JOIN"
+	OperationPartitionLeave            = "PARTITION_LEAVE"
+	OperationPartitionReconfigure      = "PARTITION_RECONFIGURE_PRIORITY"
+	OperationPartitionForceReconfigure = "PARTITION_FORCE_RECONFIGURE"
+	OperationPartitionBootstrap        = "PARTITION_BOOTSTRAP"
+	OperationPartitionDisableExporter  = "PARTITION_DISABLE_EXPORTER"
+	OperationPartitionEnableExporter   = "PARTITION_ENABLE_EXPORTER"
+	OperationPartitionDeleteExporter   = "PARTITION_DELETE_EXPORTER"
+)
+
+type ExporterState struct {
+	Id    string `json:"id"`
+	State string `json:"state"`
+}
+
+type ExportingConfig struct {
+	Exporters []ExporterState `json:"exporters"`
+}
+
+type PartitionConfig struct {
+	Exporting ExportingConfig `json:"exporting"`
+}
+
+type PartitionState struct {
+	Id       int             `json:"id"`
+	State    string          `json:"state"`
+	Priority int             `json:"priority"`
+	Config   PartitionConfig `json:"config"`
+}
+
+type BrokerState struct {
+	Id            int              `json:"id"`
+	State         string           `json:"state"`
+	Version       int              `json:"version"`
+	LastUpdatedAt string           `json:"lastUpdatedAt"`
+	Partitions    []PartitionState `json:"partitions"`
+}
+
+type Operation struct {
+	Operation   string `json:"operation"`
+	BrokerId    int    `json:"brokerId"`
+	PartitionId int    `json:"partitionId,omitempty"`
+	Priority    int    `json:"priority,omitempty"`
+	Brokers     []int  `json:"brokers,omitempty"`
+	ExporterId  string `json:"exporterId,omitempty"`
+}
+
+type CompletedChange struct {
+	Id          int64  `json:"id"`
+	Status      string `json:"status"`
+	StartedAt   string `json:"startedAt"`
+	CompletedAt string `json:"completedAt"`
+}
+
+type TopologyChange struct {
+	Id        int64       `json:"id"`
+	Status    string      `json:"status"`
+	StartedAt string      `json:"startedAt"`
+	Completed []Operation `json:"completed"`
+	Pending   []Operation `json:"pending"`
+}
+
+// ClusterTopology is the response of GET /actuator/cluster
+type ClusterTopology struct {
+	Version       int64            `json:"version"`
+	Brokers       []BrokerState    `json:"brokers"`
+	LastChange    *CompletedChange `json:"lastChange,omitempty"`
+	PendingChange *TopologyChange  `json:"pendingChange,omitempty"`
+}
+
+// PlannedChangeResponse is the response of any request that modifies the cluster configuration
+type PlannedChangeResponse struct {
+	ChangeId         int64         `json:"changeId"`
+	CurrentTopology  []BrokerState `json:"currentTopology"`
+	PlannedChanges   []Operation   `json:"plannedChanges"`
+	ExpectedTopology []BrokerState `json:"expectedTopology"`
+}
+
+type BrokersChange struct {
+	Add    []int `json:"add,omitempty"`
+	Remove []int `json:"remove,omitempty"`
+}
+
+type PartitionsChange struct {
+	Count             int `json:"count,omitempty"`
+	ReplicationFactor int `json:"replicationFactor,omitempty"`
+}
+
+// ClusterChangeRequest is the payload of PATCH /actuator/cluster
+type ClusterChangeRequest struct {
+	Brokers    *BrokersChange    `json:"brokers,omitempty"`
+	Partitions *PartitionsChange `json:"partitions,omitempty"`
+}
+
+// PatchOptions are passed as query parameters to PATCH /actuator/cluster
+type PatchOptions struct {
+	DryRun bool
+	// Force sends force=true, only if the gate allows the change
+	Force *ForceRemovalGate
+}
+
+// HasPendingChange reports whether a cluster change is currently in progress
+func (c ClusterTopology) HasPendingChange() bool {
+	return c.PendingChange != nil
+}
+
+// Broker returns the broker with the given id, or nil if it is not part of the topology
+func (c ClusterTopology) Broker(id int) *BrokerState {
+	for i := range c.Brokers {
+		if c.Brokers[i].Id == id {
+			return &c.Brokers[i]
+		}
+	}
+	return nil
+}
+
+// BrokerIds returns the sorted ids of all brokers in the topology
+func (c ClusterTopology) BrokerIds() []int {
+	ids := make([]int, 0, len(c.Brokers))
+	for _, broker := range c.Brokers {
+		ids = append(ids, broker.Id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Progress returns the number of completed operations and the total number of operations of the change
+func (c TopologyChange) Progress() (int, int) {
+	return len(c.Completed), len(c.Completed) + len(c.Pending)
+}
+
+// HasOperation reports whether any planned change is of the given operation type
+func (r PlannedChangeResponse) HasOperation(operation string) bool {
+	for _, op := range r.PlannedChanges {
+		if op.Operation == operation {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpectedBroker returns the broker with the given id from the expected topology, or nil if it is not part of it
+func (r PlannedChangeResponse) ExpectedBroker(id int) *BrokerState {
+	for i := range r.ExpectedTopology {
+		if r.ExpectedTopology[i].Id == id {
+			return &r.ExpectedTopology[i]
+		}
+	}
+	return nil
+}
+
+// GetCluster returns the current cluster topology
+func (c *ActuatorClient) GetCluster() (ClusterTopology, error) {
+	var topology ClusterTopology
+	err := c.do(http.MethodGet, "/actuator/cluster", nil, nil, &topology)
+	return topology, err
+}
+
+// PatchCluster requests a change of brokers and/or partitions, a forced change is refused unless its gate allows it
+func (c *ActuatorClient) PatchCluster(change ClusterChangeRequest, opts PatchOptions) (PlannedChangeResponse, error) {
+	query := url.Values{}
+	if opts.DryRun {
+		query.Set("dryRun", "true")
+	}
+	if opts.Force != nil {
+		if err := opts.Force.Allows(change); err != nil {
+			return PlannedChangeResponse{}, err
+		}
+		query.Set("force", "true")
+	}
+
+	var response PlannedChangeResponse
+	err := c.do(http.MethodPatch, "/actuator/cluster", query, change, &response)
+	return response, err
+}
+
+// PendingChangeTimeoutError is returned when a cluster change is still pending after all retries
+type PendingChangeTimeoutError struct {
+	Change *TopologyChange
+}
+
+func (e *PendingChangeTimeoutError) Error() string {
+	if e.Change == nil {
+		return "cluster change still pending"
+	}
+	return "cluster change " + formatChangeProgress(*e.Change) + " still pending"
+}
+
+func formatChangeProgress(change TopologyChange) string {
+	completed, total := change.Progress()
+	return fmt.Sprintf("%d (%s, %d/%d operations)", change.Id, change.Status, completed, total)
+}
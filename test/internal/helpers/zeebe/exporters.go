@@ -0,0 +1,198 @@
+package zeebeHelpers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Exporter states as reported by /actuator/exporters and the partition configuration
+const (
+	ExporterStatusEnabled        = "ENABLED"
+	ExporterStatusDisabled       = "DISABLED"
+	ExporterStatusConfigNotFound = "CONFIG_NOT_FOUND"
+	// ExporterStatusDeleted is not reported by Zeebe, it marks an exporter that is gone from every partition
+	ExporterStatusDeleted = ""
+)
+
+// ExporterStatus is an entry of the response of GET /actuator/exporters
+type ExporterStatus struct {
+	ExporterId string `json:"exporterId"`
+	Status     string `json:"status"`
+}
+
+type enableExporterRequest struct {
+	InitializeFrom string `json:"initializeFrom,omitempty"`
+}
+
+// CamundaExporterId returns the id of the Camunda exporter that writes to the Elasticsearch of the given region
+func CamundaExporterId(regionId int) string {
+	return fmt.Sprintf("camundaregion%d", regionId)
+}
+
+// ElasticsearchExporterId returns the id of the pre 8.8 Elasticsearch exporter of the given region, used during migrations
+func ElasticsearchExporterId(regionId int) string {
+	return fmt.Sprintf("elasticsearchregion%d", regionId)
+}
+
+// GetExporters lists all configured exporters with their status
+func (c *ActuatorClient) GetExporters() ([]ExporterStatus, error) {
+	var exporters []ExporterStatus
+	err := c.do(http.MethodGet, "/actuator/exporters", nil, nil, &exporters)
+	return exporters, err
+}
+
+// EnableExporter enables the exporter, initializing its state from initializeFrom if it is not empty
+func (c *ActuatorClient) EnableExporter(exporterId, initializeFrom string) (PlannedChangeResponse, error) {
+	var response PlannedChangeResponse
+	err := c.do(http.MethodPost, fmt.Sprintf("/actuator/exporters/%s/enable", exporterId), nil, enableExporterRequest{InitializeFrom: initializeFrom}, &response)
+	return response, err
+}
+
+// DisableExporter disables the exporter on all partitions
+func (c *ActuatorClient) DisableExporter(exporterId string) (PlannedChangeResponse, error) {
+	var response PlannedChangeResponse
+	err := c.do(http.MethodPost, fmt.Sprintf("/actuator/exporters/%s/disable", exporterId), nil, nil, &response)
+	return response, err
+}
+
+// DeleteExporter removes the exporter and its state from all partitions
+func (c *ActuatorClient) DeleteExporter(exporterId string) (PlannedChangeResponse, error) {
+	var response PlannedChangeResponse
+	err := c.do(http.MethodPost, fmt.Sprintf("/actuator/exporters/%s/delete", exporterId), nil, nil, &response)
+	return response, err
+}
+
+// ExporterStatusOf returns the status of the exporter from the list, or ExporterStatusDeleted if it is not listed
+func ExporterStatusOf(exporters []ExporterStatus, exporterId string) string {
+	for _, exporter := range exporters {
+		if exporter.ExporterId == exporterId {
+			return exporter.Status
+		}
+	}
+	return ExporterStatusDeleted
+}
+
+// PartitionsWithExporterState returns the "broker/partition" pairs that do not report the given state for the exporter
+// An empty result means every partition replica agrees on the state
+func PartitionsWithExporterState(topology ClusterTopology, exporterId, state string) []string {
+	var mismatches []string
+	for _, broker := range topology.Brokers {
+		for _, partition := range broker.Partitions {
+			current := ExporterStatusDeleted
+			for _, exporter := range partition.Config.Exporting.Exporters {
+				if exporter.Id == exporterId {
+					current = exporter.State
+				}
+			}
+			if current != state {
+				mismatches = append(mismatches, fmt.Sprintf("%d/%d=%s", broker.Id, partition.Id, current))
+			}
+		}
+	}
+	return mismatches
+}
+
+// ExporterManager enables, disables and deletes exporters and waits until every partition reports the new state
+type ExporterManager struct {
+	Client     *ActuatorClient
+	MaxRetries int
+	Interval   time.Duration
+}
+
+// NewExporterManager creates an ExporterManager with the default timeouts, allowing for slow exporter initialization
+func NewExporterManager(client *ActuatorClient) *ExporterManager {
+	return &ExporterManager{
+		Client:     client,
+		MaxRetries: 30,
+		Interval:   15 * time.Second,
+	}
+}
+
+// List returns all exporters with their status
+func (m *ExporterManager) List(t *testing.T) []ExporterStatus {
+	t.Helper()
+
+	exporters, err := m.Client.GetExporters()
+	require.NoError(t, err, "[EXPORTERS] Failed to list exporters")
+	return exporters
+}
+
+// Enable enables the exporter, initialized from initializeFrom, and waits until all partitions have it enabled
+func (m *ExporterManager) Enable(t *testing.T, exporterId, initializeFrom string) {
+	t.Helper()
+	t.Logf("[EXPORTERS] Enabling exporter %s (initialize from %q)", exporterId, initializeFrom)
+
+	response, err := m.Client.EnableExporter(exporterId, initializeFrom)
+	require.NoError(t, err, "[EXPORTERS] Failed to enable exporter %s", exporterId)
+	require.True(t, response.HasOperation(OperationPartitionEnableExporter), "[EXPORTERS] Expected %s operations", OperationPartitionEnableExporter)
+
+	m.waitForState(t, response.ChangeId, exporterId, ExporterStatusEnabled)
+}
+
+// Disable disables the exporter and waits until all partitions have it disabled
+func (m *ExporterManager) Disable(t *testing.T, exporterId string) {
+	t.Helper()
+	t.Logf("[EXPORTERS] Disabling exporter %s", exporterId)
+
+	response, err := m.Client.DisableExporter(exporterId)
+	require.NoError(t, err, "[EXPORTERS] Failed to disable exporter %s", exporterId)
+	require.True(t, response.HasOperation(OperationPartitionDisableExporter), "[EXPORTERS] Expected %s operations", OperationPartitionDisableExporter)
+
+	m.waitForState(t, response.ChangeId, exporterId, ExporterStatusDisabled)
+}
+
+// Delete deletes the exporter and waits until no partition knows about it anymore
+func (m *ExporterManager) Delete(t *testing.T, exporterId string) {
+	t.Helper()
+	t.Logf("[EXPORTERS] Deleting exporter %s", exporterId)
+
+	response, err := m.Client.DeleteExporter(exporterId)
+	require.NoError(t, err, "[EXPORTERS] Failed to delete exporter %s", exporterId)
+	require.True(t, response.HasOperation(OperationPartitionDeleteExporter), "[EXPORTERS] Expected %s operations", OperationPartitionDeleteExporter)
+
+	m.waitForState(t, response.ChangeId, exporterId, ExporterStatusDeleted)
+}
+
+// RequireStatus asserts the status of every given exporter as reported by /actuator/exporters
+func (m *ExporterManager) RequireStatus(t *testing.T, expected map[string]string) {
+	t.Helper()
+
+	exporters := m.List(t)
+	for exporterId, status := range expected {
+		require.Equal(t, status, ExporterStatusOf(exporters, exporterId), "[EXPORTERS] Unexpected status of exporter %s", exporterId)
+	}
+}
+
+func (m *ExporterManager) waitForState(t *testing.T, changeId int64, exporterId, state string) {
+	t.Helper()
+
+	WaitForClusterChange(t, m.Client, changeId, fmt.Sprintf("exporter %s change", exporterId), m.MaxRetries, m.Interval)
+
+	var mismatches []string
+	for i := 0; i < m.MaxRetries; i++ {
+		topology, err := m.Client.GetCluster()
+		require.NoError(t, err, "[EXPORTERS] Failed to query cluster topology")
+
+		mismatches = PartitionsWithExporterState(topology, exporterId, state)
+		if len(mismatches) == 0 {
+			break
+		}
+		t.Logf("[EXPORTERS] Exporter %s not yet %s on all partitions, pending broker/partition: %v", exporterId, describeExporterState(state), mismatches)
+		time.Sleep(m.Interval)
+	}
+	require.Empty(t, mismatches, "[EXPORTERS] Exporter %s did not become %s on all partitions", exporterId, describeExporterState(state))
+
+	require.Equal(t, state, ExporterStatusOf(m.List(t), exporterId), "[EXPORTERS] Unexpected status of exporter %s", exporterId)
+	t.Logf("[EXPORTERS] Exporter %s is %s on all partitions", exporterId, describeExporterState(state))
+}
+
+func describeExporterState(state string) string {
+	if state == ExporterStatusDeleted {
+		return "deleted"
+	}
+	return state
+}
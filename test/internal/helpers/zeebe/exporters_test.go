@@ -0,0 +1,40 @@
+package zeebeHelpers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionsWithExporterState(t *testing.T) {
+	topology := ClusterTopology{Brokers: []BrokerState{
+		{Id: 0, Partitions: []PartitionState{
+			{Id: 1, Config: PartitionConfig{Exporting: ExportingConfig{Exporters: []ExporterState{{Id: "camundaregion1", State: ExporterStatusDisabled}}}}},
+			{Id: 2, Config: PartitionConfig{Exporting: ExportingConfig{Exporters: []ExporterState{{Id: "camundaregion1", State: ExporterStatusEnabled}}}}},
+		}},
+		{Id: 2, Partitions: []PartitionState{{Id: 1}}},
+	}}
+
+	require.Equal(t, []string{"0/2=ENABLED", "2/1="}, PartitionsWithExporterState(topology, CamundaExporterId(1), ExporterStatusDisabled))
+	require.Equal(t, []string{"0/1=DISABLED", "0/2=ENABLED"}, PartitionsWithExporterState(topology, CamundaExporterId(1), ExporterStatusDeleted))
+}
+
+func TestEnableExporterSendsInitializeFrom(t *testing.T) {
+	client := newActuatorTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/actuator/exporters/camundaregion2/enable", r.URL.Path)
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, map[string]string{"initializeFrom": "camundaregion0"}, body)
+
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, `{"changeId": 2, "plannedChanges": [{"operation": "PARTITION_ENABLE_EXPORTER", "brokerId": 0, "partitionId": 1, "exporterId": "camundaregion2"}]}`)
+	})
+
+	response, err := client.EnableExporter(CamundaExporterId(2), CamundaExporterId(0))
+	require.NoError(t, err)
+	require.True(t, response.HasOperation(OperationPartitionEnableExporter))
+}
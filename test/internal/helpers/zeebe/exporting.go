@@ -0,0 +1,211 @@
+package zeebeHelpers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	kubectlHelpers "multiregiontests/internal/helpers/kubectl"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/stretchr/testify/require"
+)
+
+// Exporter phases as reported per partition by /actuator/partitions of a broker
+const (
+	ExporterPhaseExporting  = "EXPORTING"
+	ExporterPhasePaused     = "PAUSED"
+	ExporterPhaseSoftPaused = "SOFT_PAUSED"
+)
+
+// PartitionRoleLeader is the raft role of the partition replica that runs the exporters
+const PartitionRoleLeader = "LEADER"
+
+// PartitionStatus is an entry of the response of GET /actuator/partitions on a broker
+type PartitionStatus struct {
+	Role              string `json:"role"`
+	SnapshotId        string `json:"snapshotId"`
+	ProcessedPosition int64  `json:"processedPosition"`
+	ExportedPosition  int64  `json:"exportedPosition"`
+	ExporterPhase     string `json:"exporterPhase"`
+}
+
+// PauseExporting pauses exporting on all partitions, a soft pause keeps exporting but stops deleting exported records
+func (c *ActuatorClient) PauseExporting(soft bool) error {
+	query := url.Values{}
+	if soft {
+		query.Set("soft", "true")
+	}
+	return c.do(http.MethodPost, "/actuator/exporting/pause", query, nil, nil)
+}
+
+// ResumeExporting resumes exporting on all partitions after a hard or soft pause
+func (c *ActuatorClient) ResumeExporting() error {
+	return c.do(http.MethodPost, "/actuator/exporting/resume", nil, nil, nil)
+}
+
+// GetPartitions returns the status of all partitions hosted by the broker, keyed by partition id
+// Only available on the management port of a broker, not on the gateway
+func (c *ActuatorClient) GetPartitions() (map[string]PartitionStatus, error) {
+	partitions := map[string]PartitionStatus{}
+	err := c.do(http.MethodGet, "/actuator/partitions", nil, nil, &partitions)
+	return partitions, err
+}
+
+// ExportingControl pauses and resumes exporting through the gateway and verifies the result on every broker
+type ExportingControl struct {
+	Gateway    *k8s.KubectlOptions
+	Brokers    []*k8s.KubectlOptions
+	MaxRetries int
+	Interval   time.Duration
+}
+
+// NewExportingControl creates an ExportingControl for the gateway in gateway and the brokers in all given namespaces
+func NewExportingControl(gateway *k8s.KubectlOptions, brokers ...*k8s.KubectlOptions) *ExportingControl {
+	return &ExportingControl{
+		Gateway:    gateway,
+		Brokers:    brokers,
+		MaxRetries: 10,
+		Interval:   30 * time.Second,
+	}
+}
+
+// Pause pauses exporting and waits until every partition leader reports the paused phase
+func (e *ExportingControl) Pause(t *testing.T, soft bool) {
+	t.Helper()
+
+	phase := ExporterPhasePaused
+	if soft {
+		phase = ExporterPhaseSoftPaused
+	}
+
+	t.Logf("[ZEEBE EXPORTING] Pausing exporting (soft: %t) 🚀", soft)
+	e.callGateway(t, "pause", func(client *ActuatorClient) error { return client.PauseExporting(soft) })
+	e.WaitForPhase(t, phase)
+}
+
+// Resume resumes exporting and waits until every partition leader is exporting again
+func (e *ExportingControl) Resume(t *testing.T) {
+	t.Helper()
+
+	t.Log("[ZEEBE EXPORTING] Resuming exporting 🚀")
+	e.callGateway(t, "resume", func(client *ActuatorClient) error { return client.ResumeExporting() })
+	e.WaitForPhase(t, ExporterPhaseExporting)
+}
+
+// WaitForPhase waits until the leader of every partition in the topology of the gateway reports the given exporter phase
+func (e *ExportingControl) WaitForPhase(t *testing.T, phase string) {
+	t.Helper()
+
+	var mismatches []string
+	for i := 0; i < e.MaxRetries; i++ {
+		topology, err := kubectlHelpers.GetClusterTopologyE(t, e.Gateway)
+		if err == nil && topology.PartitionsCount == 0 {
+			err = fmt.Errorf("the topology has no partitions")
+		}
+
+		if err != nil {
+			mismatches = []string{fmt.Sprintf("topology: %v", err)}
+		} else {
+			phases := e.LeaderPhases(t)
+			mismatches = PhaseMismatches(phases, topology.PartitionsCount, phase)
+		}
+
+		if len(mismatches) == 0 {
+			t.Logf("[ZEEBE EXPORTING] The leaders of all partitions report %s", phase)
+			return
+		}
+		t.Logf("[ZEEBE EXPORTING] Partitions not yet %s: %v (attempt %d/%d)", phase, mismatches, i+1, e.MaxRetries)
+		time.Sleep(e.Interval)
+	}
+
+	t.Fatalf("[ZEEBE EXPORTING] Partitions did not reach %s: %v", phase, mismatches)
+}
+
+// PhaseMismatches lists the leaders of partitions 1 to partitionsCount that are not in phase and the partitions without an observed leader
+// phases is keyed by "namespace/pod/partition" like LeaderPhases
+func PhaseMismatches(phases map[string]string, partitionsCount int, phase string) []string {
+	var mismatches []string
+	covered := map[string]bool{}
+	for replica, current := range phases {
+		covered[replica[strings.LastIndex(replica, "/")+1:]] = true
+		if current != phase {
+			mismatches = append(mismatches, fmt.Sprintf("%s=%s", replica, current))
+		}
+	}
+	for id := 1; id <= partitionsCount; id++ {
+		if !covered[strconv.Itoa(id)] {
+			mismatches = append(mismatches, fmt.Sprintf("partition %d has no observed leader", id))
+		}
+	}
+	sort.Strings(mismatches)
+	return mismatches
+}
+
+// LeaderPhases returns the exporter phase of every partition leader, keyed by "namespace/pod/partition"
+func (e *ExportingControl) LeaderPhases(t *testing.T) map[string]string {
+	t.Helper()
+
+	phases := map[string]string{}
+	for _, options := range e.Brokers {
+		replicas, err := kubectlHelpers.GetStatefulSetReplicasE(t, options, "camunda-zeebe")
+		if errors.Is(err, kubectlHelpers.ErrStatefulSetNotFound) {
+			t.Logf("[ZEEBE EXPORTING] No brokers in namespace %s, skipping", options.Namespace)
+			continue
+		}
+		require.NoError(t, err)
+
+		for i := 0; i < replicas; i++ {
+			podName := fmt.Sprintf("camunda-zeebe-%d", i)
+			pod, err := k8s.GetPodE(t, options, podName)
+			if err != nil || pod.Status.Phase != "Running" {
+				t.Logf("[ZEEBE EXPORTING] Broker %s/%s is not running, skipping", options.Namespace, podName)
+				continue
+			}
+
+			partitions := getBrokerPartitions(t, options, podName)
+			for partitionId, status := range partitions {
+				if status.Role == PartitionRoleLeader {
+					phases[fmt.Sprintf("%s/%s/%s", options.Namespace, podName, partitionId)] = status.ExporterPhase
+				}
+			}
+		}
+	}
+	return phases
+}
+
+// callGateway retries the call on server errors, as partition distribution may take a while and results in a 5xx
+func (e *ExportingControl) callGateway(t *testing.T, action string, call func(client *ActuatorClient) error) {
+	t.Helper()
+
+	client, closeFn := NewActuatorTunnel(t, e.Gateway)
+	defer closeFn()
+
+	var err error
+	for i := 0; i < e.MaxRetries; i++ {
+		err = call(client)
+		if err == nil || !IsServerError(err) {
+			break
+		}
+		t.Logf("[ZEEBE EXPORTING] Failed to %s exporting, retrying... (%v)", action, err)
+		time.Sleep(e.Interval)
+	}
+	require.NoError(t, err, "[ZEEBE EXPORTING] Failed to %s exporting", action)
+}
+
+func getBrokerPartitions(t *testing.T, kubectlOptions *k8s.KubectlOptions, podName string) map[string]PartitionStatus {
+	t.Helper()
+
+	endpoint, closeFn := kubectlHelpers.NewPodTunnelWithRetry(t, kubectlOptions, podName, 0, ManagementPort, 5, 5*time.Second)
+	defer closeFn()
+
+	partitions, err := NewActuatorClient(endpoint).GetPartitions()
+	require.NoError(t, err, "[ZEEBE EXPORTING] Failed to get partitions of %s/%s", kubectlOptions.Namespace, podName)
+	return partitions
+}
@@ -0,0 +1,51 @@
+package zeebeHelpers
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauseExportingUsesSoftQuery(t *testing.T) {
+	var queries []string
+	client := newActuatorTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		queries = append(queries, r.URL.Path+"?"+r.URL.RawQuery)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	require.NoError(t, client.PauseExporting(true))
+	require.NoError(t, client.PauseExporting(false))
+	require.NoError(t, client.ResumeExporting())
+	require.Equal(t, []string{"/actuator/exporting/pause?soft=true", "/actuator/exporting/pause?", "/actuator/exporting/resume?"}, queries)
+}
+
+func TestGetPartitionsDecodesExporterPhase(t *testing.T) {
+	client := newActuatorTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"1": {"role": "LEADER", "exporterPhase": "PAUSED", "exportedPosition": 42}, "2": {"role": "FOLLOWER"}}`)
+	})
+
+	partitions, err := client.GetPartitions()
+	require.NoError(t, err)
+	require.Equal(t, ExporterPhasePaused, partitions["1"].ExporterPhase)
+	require.Equal(t, int64(42), partitions["1"].ExportedPosition)
+	require.Equal(t, "FOLLOWER", partitions["2"].Role)
+}
+
+func TestPhaseMismatchesReportsPartitionsWithoutLeader(t *testing.T) {
+	phases := map[string]string{
+		"ns-0/camunda-zeebe-0/1": ExporterPhasePaused,
+		"ns-1/camunda-zeebe-1/3": ExporterPhaseExporting,
+	}
+
+	require.Equal(t, []string{
+		"ns-1/camunda-zeebe-1/3=EXPORTING",
+		"partition 2 has no observed leader",
+	}, PhaseMismatches(phases, 3, ExporterPhasePaused))
+
+	phases["ns-1/camunda-zeebe-1/2"] = ExporterPhasePaused
+	phases["ns-1/camunda-zeebe-1/3"] = ExporterPhasePaused
+	require.Empty(t, PhaseMismatches(phases, 3, ExporterPhasePaused))
+}
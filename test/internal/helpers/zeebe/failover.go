@@ -0,0 +1,130 @@
+package zeebeHelpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	kubectlHelpers "multiregiontests/internal/helpers/kubectl"
+
+	"github.com/stretchr/testify/require"
+)
+
+// FailoverPartition describes what happens to a partition once all brokers of a region are gone
+type FailoverPartition struct {
+	PartitionId int
+	Remaining   []int
+	Lost        []int
+	KeepsQuorum bool
+}
+
+// FailoverReadiness is the simulated outcome of losing all brokers of one region
+type FailoverReadiness struct {
+	Region            int
+	ReplicationFactor int
+	BrokersToRemove   []int
+	Partitions        []FailoverPartition
+}
+
+// SimulateRegionLoss removes all brokers of the region from the live topology and reports for each partition
+// whether the remaining replicas still form a quorum of the replication factor
+func SimulateRegionLoss(topology kubectlHelpers.ClusterInfo, regions int, regionOf RegionResolver, region int) FailoverReadiness {
+	readiness := FailoverReadiness{
+		Region:            region,
+		ReplicationFactor: topology.ReplicationFactor,
+	}
+
+	for _, broker := range topology.Brokers {
+		if brokerRegion, ok := regionOf(broker); ok && brokerRegion == region {
+			readiness.BrokersToRemove = append(readiness.BrokersToRemove, broker.NodeId)
+		}
+	}
+	sort.Ints(readiness.BrokersToRemove)
+
+	report := AnalyzePlacement(topology, regions, regionOf)
+	for _, placement := range report.Partitions {
+		partition := FailoverPartition{PartitionId: placement.PartitionId}
+		for _, replica := range placement.Replicas {
+			if replica.Region == region {
+				partition.Lost = append(partition.Lost, replica.NodeId)
+			} else {
+				partition.Remaining = append(partition.Remaining, replica.NodeId)
+			}
+		}
+
+		replicationFactor := readiness.ReplicationFactor
+		if replicationFactor == 0 {
+			replicationFactor = len(placement.Replicas)
+		}
+		partition.KeepsQuorum = len(partition.Remaining) >= replicationFactor/2+1
+
+		readiness.Partitions = append(readiness.Partitions, partition)
+	}
+
+	return readiness
+}
+
+// PartitionsNeedingForceReconfigure returns the ids of all partitions that lose quorum and need a PARTITION_FORCE_RECONFIGURE
+func (f FailoverReadiness) PartitionsNeedingForceReconfigure() []int {
+	var ids []int
+	for _, partition := range f.Partitions {
+		if !partition.KeepsQuorum {
+			ids = append(ids, partition.PartitionId)
+		}
+	}
+	return ids
+}
+
+// RequiresForce reports whether removing the brokers has to be forced as some partitions lose quorum
+func (f FailoverReadiness) RequiresForce() bool {
+	return len(f.PartitionsNeedingForceReconfigure()) > 0
+}
+
+// Change returns the actuator request that removes the brokers of the lost region
+func (f FailoverReadiness) Change() ClusterChangeRequest {
+	return ClusterChangeRequest{Brokers: &BrokersChange{Remove: f.BrokersToRemove}}
+}
+
+// Request renders the actuator call that has to be sent to remove the brokers of the lost region
+func (f FailoverReadiness) Request() string {
+	// A change request of ints always marshals
+	payload, _ := json.Marshal(f.Change())
+
+	path := "/actuator/cluster"
+	if f.RequiresForce() {
+		path += "?force=true"
+	}
+	return fmt.Sprintf("PATCH %s %s", path, payload)
+}
+
+// String renders the per partition outcome followed by the actuator request
+func (f FailoverReadiness) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "losing region %d removes brokers %v (replication factor %d)\n", f.Region, f.BrokersToRemove, f.ReplicationFactor)
+	for _, partition := range f.Partitions {
+		outcome := "keeps quorum"
+		if !partition.KeepsQuorum {
+			outcome = "loses quorum, needs " + OperationPartitionForceReconfigure
+		}
+		fmt.Fprintf(&sb, "partition %d: remaining %v, lost %v -> %s\n", partition.PartitionId, partition.Remaining, partition.Lost, outcome)
+	}
+	fmt.Fprintf(&sb, "request: %s", f.Request())
+
+	return sb.String()
+}
+
+// ReportFailoverReadiness simulates losing the region on the live topology of the cluster and logs the outcome
+func ReportFailoverReadiness(t *testing.T, topology kubectlHelpers.ClusterInfo, regions int, regionOf RegionResolver, region int) FailoverReadiness {
+	t.Helper()
+
+	readiness := SimulateRegionLoss(topology, regions, regionOf, region)
+	t.Logf("[FAILOVER READINESS] Simulating the loss of region %d:\n%s", region, readiness)
+
+	require.NotEmpty(t, readiness.BrokersToRemove, "[FAILOVER READINESS] No brokers found in region %d", region)
+	require.Less(t, len(readiness.BrokersToRemove), len(topology.Brokers), "[FAILOVER READINESS] Losing region %d would remove all brokers", region)
+
+	return readiness
+}
@@ -0,0 +1,28 @@
+package zeebeHelpers
+
+import (
+	"testing"
+
+	kubectlHelpers "multiregiontests/internal/helpers/kubectl"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateRegionLoss(t *testing.T) {
+	// 6 brokers over 3 regions, replication factor 3
+	topology := kubectlHelpers.ClusterInfo{ReplicationFactor: 3, Brokers: []kubectlHelpers.Broker{
+		{NodeId: 0, Partitions: followers(1, 2)},
+		{NodeId: 1, Partitions: followers(1)},
+		{NodeId: 2, Partitions: followers(1, 2)},
+		{NodeId: 3, Partitions: followers(2)},
+		{NodeId: 4},
+		{NodeId: 5},
+	}}
+
+	readiness := SimulateRegionLoss(topology, 3, RegionByNodeId(3), 0)
+
+	require.Equal(t, []int{0, 3}, readiness.BrokersToRemove)
+	require.Equal(t, []int{2}, readiness.PartitionsNeedingForceReconfigure())
+	require.Equal(t, FailoverPartition{PartitionId: 1, Remaining: []int{1, 2}, Lost: []int{0}, KeepsQuorum: true}, readiness.Partitions[0])
+	require.Equal(t, `PATCH /actuator/cluster?force=true {"brokers":{"remove":[0,3]}}`, readiness.Request())
+}
@@ -0,0 +1,116 @@
+package zeebeHelpers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	kubectlHelpers "multiregiontests/internal/helpers/kubectl"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/stretchr/testify/require"
+)
+
+// Sources a broker identity can be resolved from
+const (
+	IdentitySourceTopology    = "topology"
+	IdentitySourceEnvironment = "environment"
+)
+
+// BrokerIdentity ties a broker pod to its Zeebe node id and region
+type BrokerIdentity struct {
+	Namespace string
+	Pod       string
+	PodIndex  int
+	NodeId    int
+	Region    int
+	Source    string
+}
+
+// ParseBrokerHost extracts pod and namespace from a broker host like camunda-zeebe-0.camunda-zeebe.<namespace>.svc.cluster.local
+func ParseBrokerHost(host string) (string, string, bool) {
+	parts := strings.Split(host, ".")
+	if len(parts) < 3 || parts[0] == "" || parts[2] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[2], true
+}
+
+// ResolveBrokerIdentities builds the pod -> nodeId -> region map of all broker pods of the camunda-zeebe StatefulSets
+// regions is indexed by region id, nil entries are skipped (e.g. a region that is gone)
+// The node id is taken from the topology host names and read from the pod environment for brokers that are not part of the topology
+func ResolveBrokerIdentities(t *testing.T, topology kubectlHelpers.ClusterInfo, regions ...*k8s.KubectlOptions) []BrokerIdentity {
+	t.Helper()
+
+	fromTopology := map[string]int{}
+	for _, broker := range topology.Brokers {
+		if pod, namespace, ok := ParseBrokerHost(broker.Host); ok {
+			fromTopology[namespace+"/"+pod] = broker.NodeId
+		}
+	}
+
+	var identities []BrokerIdentity
+	for region, options := range regions {
+		if options == nil {
+			continue
+		}
+
+		replicas := kubectlHelpers.GetStatefulSetReplicas(t, options, "camunda-zeebe")
+		for i := 0; i < replicas; i++ {
+			identity := BrokerIdentity{
+				Namespace: options.Namespace,
+				Pod:       fmt.Sprintf("camunda-zeebe-%d", i),
+				PodIndex:  i,
+				Region:    region,
+				Source:    IdentitySourceTopology,
+			}
+
+			nodeId, ok := fromTopology[identity.Namespace+"/"+identity.Pod]
+			if !ok {
+				nodeId = kubectlHelpers.GetZeebeBrokerId(t, options, identity.Pod)
+				identity.Source = IdentitySourceEnvironment
+			}
+			require.GreaterOrEqual(t, nodeId, 0, "[BROKER IDENTITY] Could not resolve node id of %s/%s", identity.Namespace, identity.Pod)
+			identity.NodeId = nodeId
+
+			identities = append(identities, identity)
+		}
+	}
+
+	sort.Slice(identities, func(i, j int) bool { return identities[i].NodeId < identities[j].NodeId })
+	return identities
+}
+
+// ExpectedNodeId returns the node id the Helm chart assigns to the pod index in the region
+func ExpectedNodeId(podIndex, regionId, regions int) int {
+	return podIndex*regions + regionId
+}
+
+// RegionNodeIds returns the node ids of all brokers of the region in a cluster of the given size
+func RegionNodeIds(regionId, regions, clusterSize int) []int {
+	var ids []int
+	for podIndex := 0; podIndex < clusterSize/regions; podIndex++ {
+		ids = append(ids, ExpectedNodeId(podIndex, regionId, regions))
+	}
+	return ids
+}
+
+// RequireNodeIdScheme asserts that every broker got the node id the multi-region scheme expects for its pod and region
+func RequireNodeIdScheme(t *testing.T, identities []BrokerIdentity, regions int) {
+	t.Helper()
+
+	require.NotEmpty(t, identities, "[BROKER IDENTITY] No brokers found")
+
+	seen := map[int]string{}
+	for _, identity := range identities {
+		t.Logf("[BROKER IDENTITY] %s/%s -> node %d, region %d (from %s)", identity.Namespace, identity.Pod, identity.NodeId, identity.Region, identity.Source)
+
+		name := identity.Namespace + "/" + identity.Pod
+		require.NotContains(t, seen, identity.NodeId, "[BROKER IDENTITY] Node id %d used by %s and %s", identity.NodeId, seen[identity.NodeId], name)
+		seen[identity.NodeId] = name
+
+		require.Equal(t, identity.Region, identity.NodeId%regions, "[BROKER IDENTITY] %s has node id %d which does not belong to region %d", name, identity.NodeId, identity.Region)
+		require.Equal(t, ExpectedNodeId(identity.PodIndex, identity.Region, regions), identity.NodeId, "[BROKER IDENTITY] Unexpected node id for %s", name)
+	}
+}
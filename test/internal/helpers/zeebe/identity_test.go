@@ -0,0 +1,29 @@
+package zeebeHelpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBrokerHost(t *testing.T) {
+	pod, namespace, ok := ParseBrokerHost("camunda-zeebe-12.camunda-zeebe.c8-snap-cluster-1.svc.cluster.local")
+	require.True(t, ok)
+	require.Equal(t, "camunda-zeebe-12", pod)
+	require.Equal(t, "c8-snap-cluster-1", namespace)
+
+	_, _, ok = ParseBrokerHost("localhost")
+	require.False(t, ok)
+}
+
+func TestExpectedNodeId(t *testing.T) {
+	require.Equal(t, 0, ExpectedNodeId(0, 0, 2))
+	require.Equal(t, 7, ExpectedNodeId(3, 1, 2))
+	require.Equal(t, 11, ExpectedNodeId(5, 1, 2))
+	require.Equal(t, 14, ExpectedNodeId(4, 2, 3))
+}
+
+func TestRegionNodeIds(t *testing.T) {
+	require.Equal(t, []int{1, 3, 5, 7}, RegionNodeIds(1, 2, 8))
+	require.Equal(t, []int{2, 5, 8}, RegionNodeIds(2, 3, 9))
+}
@@ -0,0 +1,207 @@
+package zeebeHelpers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	kubectlHelpers "multiregiontests/internal/helpers/kubectl"
+
+	"github.com/stretchr/testify/require"
+)
+
+// RegionResolver attributes a broker to a region, returning false if the region can't be determined
+type RegionResolver func(broker kubectlHelpers.Broker) (int, bool)
+
+// RegionByNamespace attributes brokers to the region whose namespace is part of the broker host, the index of the namespace is the region id
+func RegionByNamespace(namespaces ...string) RegionResolver {
+	return func(broker kubectlHelpers.Broker) (int, bool) {
+		for regionId, namespace := range namespaces {
+			if strings.Contains(broker.Host, "."+namespace+".") {
+				return regionId, true
+			}
+		}
+		return 0, false
+	}
+}
+
+// RegionByNodeId attributes brokers to regions following the nodeId % regions scheme of the Helm chart
+func RegionByNodeId(regions int) RegionResolver {
+	return func(broker kubectlHelpers.Broker) (int, bool) {
+		if regions < 1 || broker.NodeId < 0 {
+			return 0, false
+		}
+		return broker.NodeId % regions, true
+	}
+}
+
+// Replica is a single member of a partition
+type Replica struct {
+	NodeId int
+	Region int
+	Role   string
+	Health string
+}
+
+// PartitionPlacement lists the replicas of a partition
+type PartitionPlacement struct {
+	PartitionId int
+	Replicas    []Replica
+}
+
+// ReplicasInRegion returns how many replicas of the partition are located in the region
+func (p PartitionPlacement) ReplicasInRegion(region int) int {
+	count := 0
+	for _, replica := range p.Replicas {
+		if replica.Region == region {
+			count++
+		}
+	}
+	return count
+}
+
+// Leader returns the leading replica, or nil if the partition has no leader
+func (p PartitionPlacement) Leader() *Replica {
+	for i := range p.Replicas {
+		if p.Replicas[i].Role == "leader" {
+			return &p.Replicas[i]
+		}
+	}
+	return nil
+}
+
+// Quorum is the number of replicas needed for the partition to make progress
+func (p PartitionPlacement) Quorum() int {
+	return len(p.Replicas)/2 + 1
+}
+
+// PlacementReport describes how partition replicas are spread across regions
+type PlacementReport struct {
+	Regions      int
+	Partitions   []PartitionPlacement
+	Unattributed []int
+}
+
+// AnalyzePlacement builds the partition to replica map of the topology and attributes every broker to a region
+func AnalyzePlacement(topology kubectlHelpers.ClusterInfo, regions int, regionOf RegionResolver) PlacementReport {
+	report := PlacementReport{Regions: regions}
+	byPartition := map[int]*PartitionPlacement{}
+
+	for _, broker := range topology.Brokers {
+		region, ok := regionOf(broker)
+		if !ok {
+			report.Unattributed = append(report.Unattributed, broker.NodeId)
+			continue
+		}
+
+		for _, partition := range broker.Partitions {
+			placement, exists := byPartition[partition.PartitionId]
+			if !exists {
+				placement = &PartitionPlacement{PartitionId: partition.PartitionId}
+				byPartition[partition.PartitionId] = placement
+			}
+			placement.Replicas = append(placement.Replicas, Replica{
+				NodeId: broker.NodeId,
+				Region: region,
+				Role:   strings.ToLower(partition.Role),
+				Health: partition.Health,
+			})
+		}
+	}
+
+	for _, placement := range byPartition {
+		sort.Slice(placement.Replicas, func(i, j int) bool { return placement.Replicas[i].NodeId < placement.Replicas[j].NodeId })
+		report.Partitions = append(report.Partitions, *placement)
+	}
+	sort.Slice(report.Partitions, func(i, j int) bool { return report.Partitions[i].PartitionId < report.Partitions[j].PartitionId })
+	sort.Ints(report.Unattributed)
+
+	return report
+}
+
+// MissingRegions returns, per partition id, the regions that don't hold a replica of it
+func (r PlacementReport) MissingRegions() map[int][]int {
+	missing := map[int][]int{}
+	for _, placement := range r.Partitions {
+		for region := 0; region < r.Regions; region++ {
+			if placement.ReplicasInRegion(region) == 0 {
+				missing[placement.PartitionId] = append(missing[placement.PartitionId], region)
+			}
+		}
+	}
+	return missing
+}
+
+// LeadersPerRegion returns how many partition leaders each region hosts
+func (r PlacementReport) LeadersPerRegion() map[int]int {
+	leaders := map[int]int{}
+	for region := 0; region < r.Regions; region++ {
+		leaders[region] = 0
+	}
+	for _, placement := range r.Partitions {
+		if leader := placement.Leader(); leader != nil {
+			leaders[leader.Region]++
+		}
+	}
+	return leaders
+}
+
+// PartitionsWithoutLeader returns the ids of all partitions that currently have no leader
+func (r PlacementReport) PartitionsWithoutLeader() []int {
+	var ids []int
+	for _, placement := range r.Partitions {
+		if placement.Leader() == nil {
+			ids = append(ids, placement.PartitionId)
+		}
+	}
+	return ids
+}
+
+// QuorumLossOnRegionFailure returns the ids of the partitions that lose quorum if all brokers of the region go down
+func (r PlacementReport) QuorumLossOnRegionFailure(region int) []int {
+	var ids []int
+	for _, placement := range r.Partitions {
+		remaining := len(placement.Replicas) - placement.ReplicasInRegion(region)
+		if remaining < placement.Quorum() {
+			ids = append(ids, placement.PartitionId)
+		}
+	}
+	return ids
+}
+
+// String renders the replica placement per partition followed by the leader distribution and quorum risks
+func (r PlacementReport) String() string {
+	var sb strings.Builder
+
+	for _, placement := range r.Partitions {
+		replicas := make([]string, 0, len(placement.Replicas))
+		for _, replica := range placement.Replicas {
+			replicas = append(replicas, fmt.Sprintf("%d@region%d(%s)", replica.NodeId, replica.Region, replica.Role))
+		}
+		fmt.Fprintf(&sb, "partition %d: %s\n", placement.PartitionId, strings.Join(replicas, ", "))
+	}
+
+	leaders := r.LeadersPerRegion()
+	for region := 0; region < r.Regions; region++ {
+		fmt.Fprintf(&sb, "region %d: %d leaders, losing it breaks quorum of partitions %v\n", region, leaders[region], r.QuorumLossOnRegionFailure(region))
+	}
+
+	if len(r.Unattributed) > 0 {
+		fmt.Fprintf(&sb, "brokers without region: %v\n", r.Unattributed)
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// RequireReplicasInEveryRegion logs the placement report and fails if any partition is not replicated to every region
+func RequireReplicasInEveryRegion(t *testing.T, report PlacementReport) {
+	t.Helper()
+
+	t.Logf("[PARTITION PLACEMENT] Replica placement:\n%s", report)
+
+	require.Empty(t, report.Unattributed, "[PARTITION PLACEMENT] Brokers could not be attributed to a region")
+	require.NotEmpty(t, report.Partitions, "[PARTITION PLACEMENT] Topology contains no partitions")
+	require.Empty(t, report.MissingRegions(), "[PARTITION PLACEMENT] Partitions without replicas in some regions (partition -> regions)")
+	require.Empty(t, report.PartitionsWithoutLeader(), "[PARTITION PLACEMENT] Partitions without leader")
+}
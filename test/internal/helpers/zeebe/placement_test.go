@@ -0,0 +1,34 @@
+package zeebeHelpers
+
+import (
+	"testing"
+
+	kubectlHelpers "multiregiontests/internal/helpers/kubectl"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzePlacement(t *testing.T) {
+	topology := kubectlHelpers.ClusterInfo{Brokers: []kubectlHelpers.Broker{
+		broker(0, "ns-0", leader(1), follower(2)),
+		broker(1, "ns-1", follower(1), leader(2)),
+		broker(2, "ns-0", follower(1), follower(2), leader(3)),
+		broker(3, "ns-0", follower(3)),
+		broker(4, "unknown"),
+	}}
+
+	report := AnalyzePlacement(topology, 2, RegionByNamespace("ns-0", "ns-1"))
+
+	require.Equal(t, []int{4}, report.Unattributed)
+	require.Equal(t, map[int][]int{3: {1}}, report.MissingRegions())
+	require.Equal(t, map[int]int{0: 2, 1: 1}, report.LeadersPerRegion())
+	require.Equal(t, []int{1, 2, 3}, report.QuorumLossOnRegionFailure(0))
+	require.Equal(t, []int(nil), report.QuorumLossOnRegionFailure(1))
+	require.Contains(t, report.String(), "partition 1: 0@region0(leader), 1@region1(follower), 2@region0(follower)")
+}
+
+func TestRegionByNodeId(t *testing.T) {
+	region, ok := RegionByNodeId(3)(kubectlHelpers.Broker{NodeId: 10})
+	require.True(t, ok)
+	require.Equal(t, 1, region)
+}
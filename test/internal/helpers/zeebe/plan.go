@@ -0,0 +1,163 @@
+package zeebeHelpers
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ErrPlanMismatch is returned when the applied plan differs from the dry run, the change is already running then
+var ErrPlanMismatch = errors.New("applied plan differs from dry run")
+
+// Retries and interval to settle a change whose applied plan differs from the dry run
+const (
+	mismatchRetries  = 40
+	mismatchInterval = 15 * time.Second
+)
+
+// ApproveFunc decides whether a previewed plan may be applied, returning an error rejects it
+type ApproveFunc func(plan PlannedChangeResponse) error
+
+// PreviewClusterChange sends the change as a dry run and returns the planned operations without applying them
+func (c *ActuatorClient) PreviewClusterChange(change ClusterChangeRequest, force *ForceRemovalGate) (PlannedChangeResponse, error) {
+	return c.PatchCluster(change, PatchOptions{DryRun: true, Force: force})
+}
+
+// ApplyClusterChange previews the change, asks approve (if set) and then applies the very same change, forced if force is set
+// It returns ErrPlanMismatch if the applied plan differs from the previewed one, the returned responses are the preview and the applied change
+func (c *ActuatorClient) ApplyClusterChange(change ClusterChangeRequest, force *ForceRemovalGate, approve ApproveFunc) (PlannedChangeResponse, PlannedChangeResponse, error) {
+	preview, err := c.PreviewClusterChange(change, force)
+	if err != nil {
+		return preview, PlannedChangeResponse{}, fmt.Errorf("dry run failed: %w", err)
+	}
+
+	if approve != nil {
+		if err := approve(preview); err != nil {
+			return preview, PlannedChangeResponse{}, fmt.Errorf("plan was not approved: %w", err)
+		}
+	}
+
+	applied, err := c.PatchCluster(change, PatchOptions{Force: force})
+	if err != nil {
+		return preview, applied, err
+	}
+
+	if !PlansMatch(preview.PlannedChanges, applied.PlannedChanges) {
+		return preview, applied, fmt.Errorf("%w:\ndry run:\n%s\napplied:\n%s", ErrPlanMismatch, FormatPlan(preview), FormatPlan(applied))
+	}
+
+	return preview, applied, nil
+}
+
+// PlanAndApplyClusterChange logs the dry-run plan of the change before applying it and fails the test if the applied plan differs
+// force is the gate of RequireSafeForceRemoval for a forced change, nil otherwise
+// A differing change was already accepted, it is cancelled and rolled back before failing, a forced change is waited for instead
+// as the brokers of the lost region can't be added back
+func PlanAndApplyClusterChange(t *testing.T, client *ActuatorClient, change ClusterChangeRequest, force *ForceRemovalGate) PlannedChangeResponse {
+	t.Helper()
+
+	_, applied, err := client.ApplyClusterChange(change, force, func(plan PlannedChangeResponse) error {
+		t.Logf("[CLUSTER PLAN] Dry run result:\n%s", FormatPlan(plan))
+		return nil
+	})
+	if errors.Is(err, ErrPlanMismatch) && applied.ChangeId != 0 {
+		t.Logf("[CLUSTER PLAN] Change %d does not match the dry run: %v", applied.ChangeId, err)
+		if force == nil {
+			CancelAndRollback(t, client, applied.ChangeId, change, mismatchRetries, mismatchInterval)
+		} else if _, waitErr := client.WaitForChange(applied.ChangeId, mismatchRetries, mismatchInterval, nil); waitErr != nil {
+			t.Logf("[CLUSTER PLAN] Forced change %d did not complete: %v", applied.ChangeId, waitErr)
+		}
+	}
+	require.NoError(t, err, "[CLUSTER PLAN] Failed to apply cluster change")
+
+	t.Logf("[CLUSTER PLAN] Applied change %d matching the dry run", applied.ChangeId)
+	return applied
+}
+
+// PlansMatch reports whether both lists contain the same operations, independent of their order
+func PlansMatch(a, b []Operation) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	return reflect.DeepEqual(sortedOperations(a), sortedOperations(b))
+}
+
+// FormatPlan renders the planned operations grouped per broker and per partition
+func FormatPlan(plan PlannedChangeResponse) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Change %d with %d planned operations\n", plan.ChangeId, len(plan.PlannedChanges))
+
+	byBroker := map[int][]Operation{}
+	byPartition := map[int][]Operation{}
+	for _, op := range plan.PlannedChanges {
+		byBroker[op.BrokerId] = append(byBroker[op.BrokerId], op)
+		if op.PartitionId != 0 {
+			byPartition[op.PartitionId] = append(byPartition[op.PartitionId], op)
+		}
+	}
+
+	sb.WriteString("Per broker:\n")
+	for _, id := range sortedKeys(byBroker) {
+		fmt.Fprintf(&sb, "  broker %d:\n", id)
+		for _, op := range byBroker[id] {
+			fmt.Fprintf(&sb, "    - %s\n", describeOperation(op, false))
+		}
+	}
+
+	if len(byPartition) > 0 {
+		sb.WriteString("Per partition:\n")
+		for _, id := range sortedKeys(byPartition) {
+			fmt.Fprintf(&sb, "  partition %d:\n", id)
+			for _, op := range byPartition[id] {
+				fmt.Fprintf(&sb, "    - %s\n", describeOperation(op, true))
+			}
+		}
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func describeOperation(op Operation, withBroker bool) string {
+	parts := []string{op.Operation}
+	if withBroker {
+		parts = append(parts, fmt.Sprintf("broker %d", op.BrokerId))
+	} else if op.PartitionId != 0 {
+		parts = append(parts, fmt.Sprintf("partition %d", op.PartitionId))
+	}
+	if op.Priority != 0 {
+		parts = append(parts, fmt.Sprintf("priority %d", op.Priority))
+	}
+	if len(op.Brokers) > 0 {
+		parts = append(parts, fmt.Sprintf("members %v", op.Brokers))
+	}
+	if op.ExporterId != "" {
+		parts = append(parts, fmt.Sprintf("exporter %s", op.ExporterId))
+	}
+	return strings.Join(parts, " ")
+}
+
+func sortedOperations(ops []Operation) []Operation {
+	sorted := append([]Operation(nil), ops...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return describeOperation(sorted[i], true) < describeOperation(sorted[j], true)
+	})
+	return sorted
+}
+
+func sortedKeys[K cmp.Ordered, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,49 @@
+package zeebeHelpers
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyClusterChangeComparesWithDryRun(t *testing.T) {
+	var calls []string
+	applied := `{"changeId": 3, "plannedChanges": [{"operation": "BROKER_ADD", "brokerId": 8}, {"operation": "PARTITION_JOIN", "brokerId": 8, "partitionId": 2, "priority": 1}]}`
+	client := newActuatorTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.URL.RawQuery)
+		w.WriteHeader(http.StatusAccepted)
+		if r.URL.Query().Get("dryRun") == "true" {
+			io.WriteString(w, `{"changeId": 3, "plannedChanges": [{"operation": "PARTITION_JOIN", "brokerId": 8, "partitionId": 2, "priority": 1}, {"operation": "BROKER_ADD", "brokerId": 8}]}`)
+			return
+		}
+		io.WriteString(w, applied)
+	})
+
+	change := ClusterChangeRequest{Brokers: &BrokersChange{Add: []int{8}}}
+
+	preview, _, err := client.ApplyClusterChange(change, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"dryRun=true", ""}, calls)
+	require.Contains(t, FormatPlan(preview), "broker 8:\n    - PARTITION_JOIN partition 2 priority 1")
+	require.Contains(t, FormatPlan(preview), "partition 2:\n    - PARTITION_JOIN broker 8 priority 1")
+
+	applied = `{"changeId": 4, "plannedChanges": [{"operation": "BROKER_ADD", "brokerId": 9}]}`
+	_, _, err = client.ApplyClusterChange(change, nil, nil)
+	require.ErrorIs(t, err, ErrPlanMismatch)
+}
+
+func TestApplyClusterChangeStopsWhenRejected(t *testing.T) {
+	calls := 0
+	client := newActuatorTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		io.WriteString(w, `{"changeId": 1, "plannedChanges": []}`)
+	})
+
+	_, _, err := client.ApplyClusterChange(ClusterChangeRequest{}, nil, func(PlannedChangeResponse) error {
+		return io.EOF
+	})
+	require.ErrorContains(t, err, "plan was not approved")
+	require.Equal(t, 1, calls)
+}
@@ -0,0 +1,122 @@
+package zeebeHelpers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// CancelChange cancels the pending cluster change, operations that already completed are not reverted
+func (c *ActuatorClient) CancelChange(changeId int64) (ClusterTopology, error) {
+	var topology ClusterTopology
+	err := c.do(http.MethodPost, fmt.Sprintf("/actuator/cluster/changes/%d/cancel", changeId), nil, nil, &topology)
+	return topology, err
+}
+
+// CompensatingChange returns the change that reverts the broker operations of original that took effect before it was cancelled
+// Brokers that were added are removed again and brokers that already left are added back
+// Partition count and replication factor changes can't be reverted this way, the second return value lists what is left as is
+func CompensatingChange(topology ClusterTopology, original ClusterChangeRequest) (ClusterChangeRequest, []string) {
+	var compensation BrokersChange
+	var notReverted []string
+
+	if original.Brokers != nil {
+		for _, id := range original.Brokers.Add {
+			if broker := topology.Broker(id); broker != nil && broker.State != BrokerStateLeft {
+				compensation.Remove = append(compensation.Remove, id)
+			}
+		}
+		for _, id := range original.Brokers.Remove {
+			if broker := topology.Broker(id); broker == nil || broker.State == BrokerStateLeft {
+				compensation.Add = append(compensation.Add, id)
+			}
+		}
+	}
+	sort.Ints(compensation.Add)
+	sort.Ints(compensation.Remove)
+
+	if original.Partitions != nil {
+		if original.Partitions.Count > 0 {
+			notReverted = append(notReverted, fmt.Sprintf("partition count %d", original.Partitions.Count))
+		}
+		if original.Partitions.ReplicationFactor > 0 {
+			notReverted = append(notReverted, fmt.Sprintf("replication factor %d", original.Partitions.ReplicationFactor))
+		}
+	}
+
+	if len(compensation.Add) == 0 && len(compensation.Remove) == 0 {
+		return ClusterChangeRequest{}, notReverted
+	}
+	return ClusterChangeRequest{Brokers: &compensation}, notReverted
+}
+
+// IsEmpty reports whether the request contains no changes
+func (r ClusterChangeRequest) IsEmpty() bool {
+	return r.Brokers == nil && r.Partitions == nil
+}
+
+// CancelAndRollback cancels the change, logs the state the cluster is left in and applies the compensating change
+// Returns the topology once the compensating change completed, or the topology after the cancellation if there is nothing to revert
+func CancelAndRollback(t *testing.T, client *ActuatorClient, changeId int64, original ClusterChangeRequest, maxRetries int, interval time.Duration) ClusterTopology {
+	t.Helper()
+	t.Logf("[CLUSTER ROLLBACK] Cancelling cluster change %d 🛑", changeId)
+
+	_, err := client.CancelChange(changeId)
+	require.NoError(t, err, "[CLUSTER ROLLBACK] Failed to cancel change %d", changeId)
+
+	topology, err := client.WaitForChange(changeId, maxRetries, interval, nil)
+	var failed *ChangeFailedError
+	if errors.As(err, &failed) {
+		t.Logf("[CLUSTER ROLLBACK] Change %d ended with status %s", changeId, failed.Status)
+	} else {
+		require.NoError(t, err, "[CLUSTER ROLLBACK] Change %d did not stop after cancelling", changeId)
+		t.Logf("[CLUSTER ROLLBACK] Change %d completed before it could be cancelled", changeId)
+	}
+
+	for _, broker := range topology.Brokers {
+		t.Logf("[CLUSTER ROLLBACK] Broker %d is %s with %d partitions", broker.Id, broker.State, len(broker.Partitions))
+	}
+
+	compensation, notReverted := CompensatingChange(topology, original)
+	if len(notReverted) > 0 {
+		t.Logf("[CLUSTER ROLLBACK] Can't revert %v, the cluster keeps these settings", notReverted)
+	}
+	if compensation.IsEmpty() {
+		t.Log("[CLUSTER ROLLBACK] No broker changes to revert")
+		return topology
+	}
+
+	t.Logf("[CLUSTER ROLLBACK] Applying compensating change: add %v, remove %v", compensation.Brokers.Add, compensation.Brokers.Remove)
+	response, err := client.PatchCluster(compensation, PatchOptions{})
+	require.NoError(t, err, "[CLUSTER ROLLBACK] Failed to apply compensating change")
+
+	return WaitForClusterChange(t, client, response.ChangeId, "compensating change", maxRetries, interval)
+}
+
+// WaitForClusterChangeOrRollback waits for the change like WaitForClusterChange, but cancels and rolls it back if it is
+// still pending after all retries
+func WaitForClusterChangeOrRollback(t *testing.T, client *ActuatorClient, changeId int64, original ClusterChangeRequest, operationName string, maxRetries int, interval time.Duration) ClusterTopology {
+	t.Helper()
+	t.Logf("[CLUSTER CHANGE] Waiting for %s (change %d) to complete 🕐", operationName, changeId)
+
+	topology, err := client.WaitForChange(changeId, maxRetries, interval, func(attempt int, change TopologyChange) {
+		completed, total := change.Progress()
+		t.Logf("[CLUSTER CHANGE] %s in progress: %d/%d operations completed (attempt %d/%d)", operationName, completed, total, attempt, maxRetries)
+	})
+
+	var timeout *PendingChangeTimeoutError
+	if errors.As(err, &timeout) {
+		t.Logf("[CLUSTER CHANGE] %s timed out: %v", operationName, err)
+		CancelAndRollback(t, client, changeId, original, maxRetries, interval)
+		t.Fatalf("[CLUSTER CHANGE] %s did not complete and was rolled back", operationName)
+	}
+	require.NoError(t, err, "[CLUSTER CHANGE] %s did not complete", operationName)
+
+	t.Logf("[CLUSTER CHANGE] %s (change %d) completed successfully", operationName, changeId)
+	return topology
+}
@@ -0,0 +1,42 @@
+package zeebeHelpers
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancelChange(t *testing.T) {
+	client := newActuatorTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/actuator/cluster/changes/7/cancel", r.URL.Path)
+		io.WriteString(w, clusterResponse)
+	})
+
+	topology, err := client.CancelChange(7)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1}, topology.BrokerIds())
+}
+
+func TestCompensatingChange(t *testing.T) {
+	topology := ClusterTopology{Brokers: []BrokerState{
+		{Id: 0, State: BrokerStateActive},
+		{Id: 1, State: BrokerStateActive},
+		{Id: 3, State: BrokerStateJoining},
+		{Id: 4, State: BrokerStateLeft},
+	}}
+
+	compensation, notReverted := CompensatingChange(topology, ClusterChangeRequest{
+		Brokers:    &BrokersChange{Add: []int{3, 1, 5}, Remove: []int{4, 0}},
+		Partitions: &PartitionsChange{ReplicationFactor: 4},
+	})
+	require.Equal(t, &BrokersChange{Add: []int{4}, Remove: []int{1, 3}}, compensation.Brokers)
+	require.Nil(t, compensation.Partitions)
+	require.Equal(t, []string{"replication factor 4"}, notReverted)
+
+	compensation, notReverted = CompensatingChange(topology, ClusterChangeRequest{Partitions: &PartitionsChange{Count: 12}})
+	require.True(t, compensation.IsEmpty())
+	require.Equal(t, []string{"partition count 12"}, notReverted)
+}
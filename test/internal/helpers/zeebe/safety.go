@@ -0,0 +1,133 @@
+package zeebeHelpers
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	kubectlHelpers "multiregiontests/internal/helpers/kubectl"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/stretchr/testify/require"
+)
+
+// ForceRemovalGate holds everything that has to be true before brokers of a region may be force removed
+type ForceRemovalGate struct {
+	// Region is the region the caller explicitly named as lost
+	Region  int
+	Regions int
+	Brokers []int
+	// RegionOf attributes the brokers still visible to the gateway of a surviving region
+	RegionOf RegionResolver
+
+	// Topology is the view of the gateway of a surviving region
+	Topology kubectlHelpers.ClusterInfo
+	// StatefulSetReplicas and StatefulSetErr are the result of looking up camunda-zeebe in the lost region
+	StatefulSetReplicas int
+	StatefulSetErr      error
+}
+
+// Violations returns the reasons the force removal is not safe, empty if all checks pass
+func (g ForceRemovalGate) Violations() []string {
+	var violations []string
+
+	if g.Regions < 2 || g.Region < 0 || g.Region >= g.Regions {
+		violations = append(violations, fmt.Sprintf("region %d is not one of the %d regions", g.Region, g.Regions))
+		return violations
+	}
+
+	if len(g.Brokers) == 0 {
+		violations = append(violations, "no brokers to remove")
+	}
+	for _, id := range g.Brokers {
+		if id%g.Regions != g.Region {
+			violations = append(violations, fmt.Sprintf("broker %d belongs to region %d, not to the named region %d", id, id%g.Regions, g.Region))
+		}
+	}
+
+	var reachable []int
+	for _, broker := range g.Topology.Brokers {
+		region, ok := g.RegionOf(broker)
+		if (ok && region == g.Region) || slices.Contains(g.Brokers, broker.NodeId) {
+			reachable = append(reachable, broker.NodeId)
+		}
+	}
+	if len(g.Topology.Brokers) == 0 {
+		violations = append(violations, "gateway reports no brokers, can't tell which region is gone")
+	}
+	if len(reachable) > 0 {
+		sort.Ints(reachable)
+		violations = append(violations, fmt.Sprintf("brokers %v of region %d are still reachable from the gateway", reachable, g.Region))
+	}
+
+	switch {
+	case errors.Is(g.StatefulSetErr, kubectlHelpers.ErrStatefulSetNotFound):
+	case g.StatefulSetErr != nil:
+		violations = append(violations, fmt.Sprintf("can't verify the camunda-zeebe StatefulSet of region %d: %v", g.Region, g.StatefulSetErr))
+	case g.StatefulSetReplicas > 0:
+		violations = append(violations, fmt.Sprintf("camunda-zeebe StatefulSet of region %d still has %d replicas", g.Region, g.StatefulSetReplicas))
+	}
+
+	return violations
+}
+
+// Allows returns an error unless all checks pass and the change only removes the brokers of the gate
+func (g ForceRemovalGate) Allows(change ClusterChangeRequest) error {
+	violations := g.Violations()
+
+	var removed []int
+	if change.Brokers != nil {
+		removed = slices.Sorted(slices.Values(change.Brokers.Remove))
+		if len(change.Brokers.Add) > 0 {
+			violations = append(violations, fmt.Sprintf("change adds brokers %v", change.Brokers.Add))
+		}
+	}
+	if change.Partitions != nil {
+		violations = append(violations, "change scales partitions")
+	}
+	if gated := slices.Sorted(slices.Values(g.Brokers)); !slices.Equal(removed, gated) {
+		violations = append(violations, fmt.Sprintf("change removes brokers %v, the gate covers %v", removed, gated))
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("refusing to force the change for region %d: %s", g.Region, strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// RequireSafeForceRemoval blocks until the brokers of the named region are gone from the view of the surviving gateway and
+// the camunda-zeebe StatefulSet of the lost region is absent or scaled to zero, failing the test if that does not happen
+// gateway points to a surviving region, lost to the namespace of the named region
+// The returned gate is what PatchOptions.Force needs to send the forced change
+func RequireSafeForceRemoval(t *testing.T, gateway, lost *k8s.KubectlOptions, region, regions int, brokers []int, regionOf RegionResolver) *ForceRemovalGate {
+	t.Helper()
+	t.Logf("[FORCE REMOVAL GATE] Verifying region %d is gone before force removing brokers %v 🔒", region, brokers)
+
+	maxRetries := 8
+	interval := 15 * time.Second
+
+	var violations []string
+	for i := 0; i < maxRetries; i++ {
+		gate := ForceRemovalGate{Region: region, Regions: regions, Brokers: brokers, RegionOf: regionOf}
+
+		topology, err := kubectlHelpers.GetClusterTopologyE(t, gateway)
+		require.NoError(t, err, "[FORCE REMOVAL GATE] Failed to get the topology from the surviving gateway")
+		gate.Topology = topology
+		gate.StatefulSetReplicas, gate.StatefulSetErr = kubectlHelpers.GetStatefulSetReplicasE(t, lost, "camunda-zeebe")
+
+		violations = gate.Violations()
+		if len(violations) == 0 {
+			t.Logf("[FORCE REMOVAL GATE] Region %d is gone, force removal of brokers %v is safe", region, brokers)
+			return &gate
+		}
+		t.Logf("[FORCE REMOVAL GATE] Not safe yet: %v (attempt %d/%d)", violations, i+1, maxRetries)
+		time.Sleep(interval)
+	}
+
+	t.Fatalf("[FORCE REMOVAL GATE] Refusing to force remove brokers %v of region %d: %v", brokers, region, violations)
+	return nil
+}
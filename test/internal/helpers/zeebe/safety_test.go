@@ -0,0 +1,53 @@
+package zeebeHelpers
+
+import (
+	"errors"
+	"testing"
+
+	kubectlHelpers "multiregiontests/internal/helpers/kubectl"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestForceRemovalGate(t *testing.T) {
+	require.Empty(t, gate().Violations())
+
+	scaledToZero := gate()
+	scaledToZero.StatefulSetErr = nil
+	require.Empty(t, scaledToZero.Violations())
+
+	stillRunning := gate()
+	stillRunning.StatefulSetErr = nil
+	stillRunning.StatefulSetReplicas = 4
+	require.Len(t, stillRunning.Violations(), 1)
+
+	unknown := gate()
+	unknown.StatefulSetErr = errors.New("connection refused")
+	require.Len(t, unknown.Violations(), 1)
+
+	reachable := gate()
+	reachable.Topology.Brokers = append(reachable.Topology.Brokers, kubectlHelpers.Broker{NodeId: 1, Host: "camunda-zeebe-0.camunda-zeebe.c8-snap-cluster-1.svc.cluster.local"})
+	require.Equal(t, []string{"brokers [1] of region 1 are still reachable from the gateway"}, reachable.Violations())
+
+	wrongRegion := gate()
+	wrongRegion.Brokers = []int{0, 1}
+	require.Equal(t, []string{
+		"broker 0 belongs to region 0, not to the named region 1",
+		"brokers [0] of region 1 are still reachable from the gateway",
+	}, wrongRegion.Violations())
+
+	unnamed := gate()
+	unnamed.Region = 2
+	require.NotEmpty(t, unnamed.Violations())
+}
+
+func TestForceRemovalGateAllows(t *testing.T) {
+	require.NoError(t, gate().Allows(ClusterChangeRequest{Brokers: &BrokersChange{Remove: []int{3, 1}}}))
+	require.ErrorContains(t, gate().Allows(ClusterChangeRequest{Brokers: &BrokersChange{Remove: []int{1}}}), "the gate covers [1 3]")
+	require.ErrorContains(t, gate().Allows(ClusterChangeRequest{Brokers: &BrokersChange{Remove: []int{1, 3}}, Partitions: &PartitionsChange{Count: 4}}), "scales partitions")
+
+	running := gate()
+	running.StatefulSetErr = nil
+	running.StatefulSetReplicas = 4
+	require.ErrorContains(t, running.Allows(ClusterChangeRequest{Brokers: &BrokersChange{Remove: []int{1, 3}}}), "still has 4 replicas")
+}
@@ -0,0 +1,92 @@
+package zeebeHelpers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	kubectlHelpers "multiregiontests/internal/helpers/kubectl"
+)
+
+// BrokerScalingPlan describes how to get from the current cluster size to the target brokers per region
+// Pod indexes are the same in every region, broker ids follow the nodeId = podIndex*regions + regionId scheme
+type BrokerScalingPlan struct {
+	Regions           int
+	CurrentPerRegion  int
+	TargetPerRegion   int
+	NewPodIndexes     []int
+	RemovedPodIndexes []int
+	BrokersToAdd      []int
+	BrokersToRemove   []int
+}
+
+// PlanBrokerScaling computes the StatefulSet replicas, pod indexes and broker ids to scale the cluster to targetPerRegion brokers per region
+func PlanBrokerScaling(topology kubectlHelpers.ClusterInfo, regions, targetPerRegion int) (BrokerScalingPlan, error) {
+	if regions < 1 {
+		return BrokerScalingPlan{}, fmt.Errorf("expected at least one region, got %d", regions)
+	}
+	if targetPerRegion < 1 {
+		return BrokerScalingPlan{}, fmt.Errorf("expected at least one broker per region, got %d", targetPerRegion)
+	}
+	if topology.ClusterSize%regions != 0 {
+		return BrokerScalingPlan{}, fmt.Errorf("cluster size %d is not divisible by %d regions", topology.ClusterSize, regions)
+	}
+
+	plan := BrokerScalingPlan{
+		Regions:          regions,
+		CurrentPerRegion: topology.ClusterSize / regions,
+		TargetPerRegion:  targetPerRegion,
+	}
+
+	existing := map[int]bool{}
+	for _, broker := range topology.Brokers {
+		existing[broker.NodeId] = true
+	}
+
+	for podIndex := plan.CurrentPerRegion; podIndex < plan.TargetPerRegion; podIndex++ {
+		plan.NewPodIndexes = append(plan.NewPodIndexes, podIndex)
+		for region := 0; region < regions; region++ {
+			nodeId := ExpectedNodeId(podIndex, region, regions)
+			if existing[nodeId] {
+				return BrokerScalingPlan{}, fmt.Errorf("broker %d for pod index %d in region %d is already part of the cluster", nodeId, podIndex, region)
+			}
+			plan.BrokersToAdd = append(plan.BrokersToAdd, nodeId)
+		}
+	}
+
+	for podIndex := plan.TargetPerRegion; podIndex < plan.CurrentPerRegion; podIndex++ {
+		plan.RemovedPodIndexes = append(plan.RemovedPodIndexes, podIndex)
+		for region := 0; region < regions; region++ {
+			plan.BrokersToRemove = append(plan.BrokersToRemove, ExpectedNodeId(podIndex, region, regions))
+		}
+	}
+
+	sort.Ints(plan.BrokersToAdd)
+	sort.Ints(plan.BrokersToRemove)
+	return plan, nil
+}
+
+// ClusterSize returns the total number of brokers once the plan is applied
+func (p BrokerScalingPlan) ClusterSize() int {
+	return p.TargetPerRegion * p.Regions
+}
+
+// Change returns the actuator request that adds or removes the brokers of the plan
+func (p BrokerScalingPlan) Change() ClusterChangeRequest {
+	return ClusterChangeRequest{Brokers: &BrokersChange{Add: p.BrokersToAdd, Remove: p.BrokersToRemove}}
+}
+
+// String renders the StatefulSet replicas, pod indexes and broker ids of the plan
+func (p BrokerScalingPlan) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%d regions, %d -> %d brokers per region (cluster size %d -> %d)\n", p.Regions, p.CurrentPerRegion, p.TargetPerRegion, p.CurrentPerRegion*p.Regions, p.ClusterSize())
+	if len(p.NewPodIndexes) > 0 {
+		fmt.Fprintf(&sb, "new pod indexes per region: %v, brokers to add: %v\n", p.NewPodIndexes, p.BrokersToAdd)
+	}
+	if len(p.RemovedPodIndexes) > 0 {
+		fmt.Fprintf(&sb, "removed pod indexes per region: %v, brokers to remove: %v\n", p.RemovedPodIndexes, p.BrokersToRemove)
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
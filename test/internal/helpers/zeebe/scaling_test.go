@@ -0,0 +1,36 @@
+package zeebeHelpers
+
+import (
+	"testing"
+
+	kubectlHelpers "multiregiontests/internal/helpers/kubectl"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanBrokerScaling(t *testing.T) {
+	plan, err := PlanBrokerScaling(topologyOfSize(8), 2, 6)
+	require.NoError(t, err)
+	require.Equal(t, []int{4, 5}, plan.NewPodIndexes)
+	require.Equal(t, []int{8, 9, 10, 11}, plan.BrokersToAdd)
+	require.Empty(t, plan.BrokersToRemove)
+	require.Equal(t, 12, plan.ClusterSize())
+
+	plan, err = PlanBrokerScaling(topologyOfSize(12), 2, 4)
+	require.NoError(t, err)
+	require.Equal(t, []int{4, 5}, plan.RemovedPodIndexes)
+	require.Equal(t, []int{8, 9, 10, 11}, plan.BrokersToRemove)
+	require.Equal(t, &BrokersChange{Remove: []int{8, 9, 10, 11}}, plan.Change().Brokers)
+
+	plan, err = PlanBrokerScaling(topologyOfSize(6), 3, 3)
+	require.NoError(t, err)
+	require.Equal(t, []int{6, 7, 8}, plan.BrokersToAdd)
+
+	_, err = PlanBrokerScaling(topologyOfSize(9), 2, 6)
+	require.Error(t, err)
+
+	inconsistent := topologyOfSize(8)
+	inconsistent.Brokers = append(inconsistent.Brokers, kubectlHelpers.Broker{NodeId: 9})
+	_, err = PlanBrokerScaling(inconsistent, 2, 5)
+	require.Error(t, err)
+}
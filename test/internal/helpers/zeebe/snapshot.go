@@ -0,0 +1,298 @@
+package zeebeHelpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	kubectlHelpers "multiregiontests/internal/helpers/kubectl"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+)
+
+// TopologySnapshot is the state of the cluster as seen by the gateway at a point in time
+type TopologySnapshot struct {
+	Label     string                     `json:"label"`
+	TakenAt   time.Time                  `json:"takenAt"`
+	Topology  kubectlHelpers.ClusterInfo `json:"topology"`
+	Exporters []ExporterStatus           `json:"exporters"`
+	Errors    []string                   `json:"errors,omitempty"`
+}
+
+// IntChange is a changed numeric property of the cluster
+type IntChange struct {
+	Before int `json:"before"`
+	After  int `json:"after"`
+}
+
+// StringChange is a changed property of a broker, partition replica or exporter, empty meaning absent
+type StringChange struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// TopologyDiff is the structured difference between two snapshots
+type TopologyDiff struct {
+	From              string                  `json:"from"`
+	To                string                  `json:"to"`
+	BrokersAdded      []int                   `json:"brokersAdded,omitempty"`
+	BrokersRemoved    []int                   `json:"brokersRemoved,omitempty"`
+	ClusterSize       *IntChange              `json:"clusterSize,omitempty"`
+	PartitionsCount   *IntChange              `json:"partitionsCount,omitempty"`
+	ReplicationFactor *IntChange              `json:"replicationFactor,omitempty"`
+	GatewayVersion    *StringChange           `json:"gatewayVersion,omitempty"`
+	BrokerVersions    map[int]StringChange    `json:"brokerVersions,omitempty"`
+	PartitionRoles    map[string]StringChange `json:"partitionRoles,omitempty"`
+	Exporters         map[string]StringChange `json:"exporters,omitempty"`
+}
+
+// DiffTopology compares two snapshots, partition roles are keyed by "partition/broker"
+func DiffTopology(before, after TopologySnapshot) TopologyDiff {
+	diff := TopologyDiff{
+		From:           before.Label,
+		To:             after.Label,
+		BrokerVersions: map[int]StringChange{},
+		PartitionRoles: map[string]StringChange{},
+		Exporters:      map[string]StringChange{},
+	}
+
+	diff.ClusterSize = intChange(before.Topology.ClusterSize, after.Topology.ClusterSize)
+	diff.PartitionsCount = intChange(before.Topology.PartitionsCount, after.Topology.PartitionsCount)
+	diff.ReplicationFactor = intChange(before.Topology.ReplicationFactor, after.Topology.ReplicationFactor)
+	if before.Topology.GatewayVersion != after.Topology.GatewayVersion {
+		diff.GatewayVersion = &StringChange{Before: before.Topology.GatewayVersion, After: after.Topology.GatewayVersion}
+	}
+
+	beforeBrokers := brokersById(before.Topology)
+	afterBrokers := brokersById(after.Topology)
+	for id, broker := range afterBrokers {
+		if _, ok := beforeBrokers[id]; !ok {
+			diff.BrokersAdded = append(diff.BrokersAdded, id)
+		} else if beforeBrokers[id].Version != broker.Version {
+			diff.BrokerVersions[id] = StringChange{Before: beforeBrokers[id].Version, After: broker.Version}
+		}
+	}
+	for id := range beforeBrokers {
+		if _, ok := afterBrokers[id]; !ok {
+			diff.BrokersRemoved = append(diff.BrokersRemoved, id)
+		}
+	}
+	sort.Ints(diff.BrokersAdded)
+	sort.Ints(diff.BrokersRemoved)
+
+	beforeRoles := partitionRoles(before.Topology)
+	afterRoles := partitionRoles(after.Topology)
+	for replica, role := range afterRoles {
+		if beforeRoles[replica] != role {
+			diff.PartitionRoles[replica] = StringChange{Before: beforeRoles[replica], After: role}
+		}
+	}
+	for replica, role := range beforeRoles {
+		if _, ok := afterRoles[replica]; !ok {
+			diff.PartitionRoles[replica] = StringChange{Before: role}
+		}
+	}
+
+	for _, exporter := range after.Exporters {
+		if previous := ExporterStatusOf(before.Exporters, exporter.ExporterId); previous != exporter.Status {
+			diff.Exporters[exporter.ExporterId] = StringChange{Before: previous, After: exporter.Status}
+		}
+	}
+	for _, exporter := range before.Exporters {
+		if ExporterStatusOf(after.Exporters, exporter.ExporterId) == ExporterStatusDeleted {
+			diff.Exporters[exporter.ExporterId] = StringChange{Before: exporter.Status}
+		}
+	}
+
+	return diff
+}
+
+// IsEmpty reports whether the snapshots describe the same cluster
+func (d TopologyDiff) IsEmpty() bool {
+	return len(d.BrokersAdded) == 0 && len(d.BrokersRemoved) == 0 &&
+		d.ClusterSize == nil && d.PartitionsCount == nil && d.ReplicationFactor == nil && d.GatewayVersion == nil &&
+		len(d.BrokerVersions) == 0 && len(d.PartitionRoles) == 0 && len(d.Exporters) == 0
+}
+
+// String renders one line per change
+func (d TopologyDiff) String() string {
+	if d.IsEmpty() {
+		return fmt.Sprintf("%s -> %s: no changes", d.From, d.To)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s -> %s:\n", d.From, d.To)
+	if len(d.BrokersAdded) > 0 {
+		fmt.Fprintf(&sb, "brokers added: %v\n", d.BrokersAdded)
+	}
+	if len(d.BrokersRemoved) > 0 {
+		fmt.Fprintf(&sb, "brokers removed: %v\n", d.BrokersRemoved)
+	}
+	if d.ClusterSize != nil {
+		fmt.Fprintf(&sb, "cluster size: %d -> %d\n", d.ClusterSize.Before, d.ClusterSize.After)
+	}
+	if d.PartitionsCount != nil {
+		fmt.Fprintf(&sb, "partitions: %d -> %d\n", d.PartitionsCount.Before, d.PartitionsCount.After)
+	}
+	if d.ReplicationFactor != nil {
+		fmt.Fprintf(&sb, "replication factor: %d -> %d\n", d.ReplicationFactor.Before, d.ReplicationFactor.After)
+	}
+	if d.GatewayVersion != nil {
+		fmt.Fprintf(&sb, "gateway version: %s\n", formatStringChange(*d.GatewayVersion))
+	}
+	for _, id := range sortedKeys(d.BrokerVersions) {
+		fmt.Fprintf(&sb, "broker %d version: %s\n", id, formatStringChange(d.BrokerVersions[id]))
+	}
+	for _, replica := range sortedKeys(d.PartitionRoles) {
+		fmt.Fprintf(&sb, "partition/broker %s role: %s\n", replica, formatStringChange(d.PartitionRoles[replica]))
+	}
+	for _, exporterId := range sortedKeys(d.Exporters) {
+		fmt.Fprintf(&sb, "exporter %s: %s\n", exporterId, formatStringChange(d.Exporters[exporterId]))
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// TopologyRecorder takes snapshots of the cluster during an operation and reports the diff between consecutive snapshots
+// Snapshots never fail the test, see SnapshotOnFailure to capture the state a failed operation left behind
+type TopologyRecorder struct {
+	Gateway   *k8s.KubectlOptions
+	Operation string
+	Dir       string
+	Snapshots []TopologySnapshot
+	Diffs     []TopologyDiff
+}
+
+// NewTopologyRecorder creates a TopologyRecorder that queries the gateway and writes the JSON report of the operation to dir
+func NewTopologyRecorder(gateway *k8s.KubectlOptions, operation, dir string) *TopologyRecorder {
+	return &TopologyRecorder{
+		Gateway:   gateway,
+		Operation: operation,
+		Dir:       dir,
+	}
+}
+
+// Snapshot captures the topology and exporters, logs the diff to the previous snapshot and rewrites the JSON report
+func (r *TopologyRecorder) Snapshot(t *testing.T, label string) TopologySnapshot {
+	t.Helper()
+
+	snapshot := CaptureTopologySnapshot(t, r.Gateway, label)
+	for _, err := range snapshot.Errors {
+		t.Logf("[TOPOLOGY SNAPSHOT] %s/%s: %s", r.Operation, label, err)
+	}
+
+	if len(r.Snapshots) > 0 {
+		diff := DiffTopology(r.Snapshots[len(r.Snapshots)-1], snapshot)
+		r.Diffs = append(r.Diffs, diff)
+		t.Logf("[TOPOLOGY SNAPSHOT] %s %s", r.Operation, diff)
+	} else {
+		t.Logf("[TOPOLOGY SNAPSHOT] %s %s: %d brokers, %d partitions, replication factor %d", r.Operation, label,
+			len(snapshot.Topology.Brokers), snapshot.Topology.PartitionsCount, snapshot.Topology.ReplicationFactor)
+	}
+	r.Snapshots = append(r.Snapshots, snapshot)
+
+	if path, err := r.WriteReport(); err != nil {
+		t.Logf("[TOPOLOGY SNAPSHOT] Failed to write report: %v", err)
+	} else {
+		t.Logf("[TOPOLOGY SNAPSHOT] Report written to %s", path)
+	}
+
+	return snapshot
+}
+
+// SnapshotOnFailure takes an "after failure" snapshot and diff once t finished, if it failed
+func (r *TopologyRecorder) SnapshotOnFailure(t *testing.T) {
+	t.Cleanup(func() {
+		if t.Failed() {
+			r.Snapshot(t, "after failure")
+		}
+	})
+}
+
+// WriteReport writes all snapshots and diffs to <dir>/<operation>.json
+func (r *TopologyRecorder) WriteReport() (string, error) {
+	if err := os.MkdirAll(r.Dir, 0755); err != nil {
+		return "", err
+	}
+
+	report, err := json.MarshalIndent(struct {
+		Operation string             `json:"operation"`
+		Snapshots []TopologySnapshot `json:"snapshots"`
+		Diffs     []TopologyDiff     `json:"diffs"`
+	}{r.Operation, r.Snapshots, r.Diffs}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(r.Dir, r.Operation+".json")
+	return path, os.WriteFile(path, report, 0644)
+}
+
+// CaptureTopologySnapshot queries the topology and exporters through the gateway, recording failures in the snapshot
+func CaptureTopologySnapshot(t *testing.T, gateway *k8s.KubectlOptions, label string) TopologySnapshot {
+	t.Helper()
+
+	snapshot := TopologySnapshot{Label: label, TakenAt: time.Now().UTC()}
+
+	topology, err := kubectlHelpers.GetClusterTopologyE(t, gateway)
+	if err != nil {
+		snapshot.Errors = append(snapshot.Errors, fmt.Sprintf("topology: %v", err))
+	}
+	snapshot.Topology = topology
+
+	endpoint, closeFn, err := kubectlHelpers.NewServiceTunnelWithRetryE(t, gateway, "camunda-zeebe-gateway", 0, ManagementPort, 3, 5*time.Second)
+	if err != nil {
+		snapshot.Errors = append(snapshot.Errors, fmt.Sprintf("exporters: %v", err))
+		return snapshot
+	}
+	defer closeFn()
+
+	exporters, err := NewActuatorClient(endpoint).GetExporters()
+	if err != nil {
+		snapshot.Errors = append(snapshot.Errors, fmt.Sprintf("exporters: %v", err))
+	}
+	sort.Slice(exporters, func(i, j int) bool { return exporters[i].ExporterId < exporters[j].ExporterId })
+	snapshot.Exporters = exporters
+
+	return snapshot
+}
+
+func intChange(before, after int) *IntChange {
+	if before == after {
+		return nil
+	}
+	return &IntChange{Before: before, After: after}
+}
+
+func formatStringChange(change StringChange) string {
+	orNone := func(value string) string {
+		if value == "" {
+			return "<none>"
+		}
+		return value
+	}
+	return fmt.Sprintf("%s -> %s", orNone(change.Before), orNone(change.After))
+}
+
+func brokersById(topology kubectlHelpers.ClusterInfo) map[int]kubectlHelpers.Broker {
+	brokers := map[int]kubectlHelpers.Broker{}
+	for _, broker := range topology.Brokers {
+		brokers[broker.NodeId] = broker
+	}
+	return brokers
+}
+
+func partitionRoles(topology kubectlHelpers.ClusterInfo) map[string]string {
+	roles := map[string]string{}
+	for _, broker := range topology.Brokers {
+		for _, partition := range broker.Partitions {
+			roles[fmt.Sprintf("%d/%d", partition.PartitionId, broker.NodeId)] = strings.ToLower(partition.Role)
+		}
+	}
+	return roles
+}
@@ -0,0 +1,73 @@
+package zeebeHelpers
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	kubectlHelpers "multiregiontests/internal/helpers/kubectl"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffTopology(t *testing.T) {
+	before := TopologySnapshot{
+		Label: "before",
+		Topology: kubectlHelpers.ClusterInfo{ClusterSize: 4, PartitionsCount: 2, ReplicationFactor: 4, GatewayVersion: "8.8.0", Brokers: []kubectlHelpers.Broker{
+			{NodeId: 0, Version: "8.8.0", Partitions: []kubectlHelpers.Partition{{PartitionId: 1, Role: "LEADER"}}},
+			{NodeId: 1, Version: "8.8.0", Partitions: []kubectlHelpers.Partition{{PartitionId: 1, Role: "FOLLOWER"}}},
+		}},
+		Exporters: []ExporterStatus{{ExporterId: "camundaregion0", Status: ExporterStatusEnabled}, {ExporterId: "camundaregion1", Status: ExporterStatusEnabled}},
+	}
+	after := TopologySnapshot{
+		Label: "after",
+		Topology: kubectlHelpers.ClusterInfo{ClusterSize: 2, PartitionsCount: 2, ReplicationFactor: 2, GatewayVersion: "8.8.0", Brokers: []kubectlHelpers.Broker{
+			{NodeId: 0, Version: "8.8.1", Partitions: []kubectlHelpers.Partition{{PartitionId: 1, Role: "FOLLOWER"}}},
+			{NodeId: 2, Version: "8.8.1", Partitions: []kubectlHelpers.Partition{{PartitionId: 1, Role: "LEADER"}}},
+		}},
+		Exporters: []ExporterStatus{{ExporterId: "camundaregion0", Status: ExporterStatusEnabled}, {ExporterId: "camundaregion1", Status: ExporterStatusDisabled}},
+	}
+
+	diff := DiffTopology(before, after)
+
+	require.Equal(t, []int{2}, diff.BrokersAdded)
+	require.Equal(t, []int{1}, diff.BrokersRemoved)
+	require.Equal(t, &IntChange{Before: 4, After: 2}, diff.ClusterSize)
+	require.Equal(t, &IntChange{Before: 4, After: 2}, diff.ReplicationFactor)
+	require.Nil(t, diff.PartitionsCount)
+	require.Nil(t, diff.GatewayVersion)
+	require.Equal(t, map[int]StringChange{0: {Before: "8.8.0", After: "8.8.1"}}, diff.BrokerVersions)
+	require.Equal(t, map[string]StringChange{
+		"1/0": {Before: "leader", After: "follower"},
+		"1/1": {Before: "follower"},
+		"1/2": {After: "leader"},
+	}, diff.PartitionRoles)
+	require.Equal(t, map[string]StringChange{"camundaregion1": {Before: ExporterStatusEnabled, After: ExporterStatusDisabled}}, diff.Exporters)
+	require.Contains(t, diff.String(), "partition/broker 1/1 role: follower -> <none>")
+
+	require.True(t, DiffTopology(before, before).IsEmpty())
+}
+
+func TestTopologyRecorderWriteReport(t *testing.T) {
+	recorder := NewTopologyRecorder(nil, "failover", t.TempDir())
+	recorder.Snapshots = []TopologySnapshot{{Label: "before"}, {Label: "after"}}
+	recorder.Diffs = []TopologyDiff{DiffTopology(recorder.Snapshots[0], recorder.Snapshots[1])}
+
+	path, err := recorder.WriteReport()
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(recorder.Dir, "failover.json"), path)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var report struct {
+		Operation string
+		Snapshots []TopologySnapshot
+		Diffs     []TopologyDiff
+	}
+	require.NoError(t, json.Unmarshal(content, &report))
+	require.Equal(t, "failover", report.Operation)
+	require.Len(t, report.Snapshots, 2)
+	require.Equal(t, "after", report.Diffs[0].To)
+}
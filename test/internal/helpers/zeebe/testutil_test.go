@@ -0,0 +1,69 @@
+package zeebeHelpers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	kubectlHelpers "multiregiontests/internal/helpers/kubectl"
+)
+
+// newActuatorTestServer returns a client of a fake management API served by handler
+func newActuatorTestServer(t *testing.T, handler http.HandlerFunc) *ActuatorClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewActuatorClient(strings.TrimPrefix(server.URL, "http://"))
+}
+
+// gate returns a gate of region 1 of 2 that is gone, which allows force removing brokers 1 and 3
+func gate() ForceRemovalGate {
+	return ForceRemovalGate{
+		Region:   1,
+		Regions:  2,
+		Brokers:  []int{1, 3},
+		RegionOf: RegionByNamespace("c8-snap-cluster-0", "c8-snap-cluster-1"),
+		Topology: kubectlHelpers.ClusterInfo{Brokers: []kubectlHelpers.Broker{
+			{NodeId: 0, Host: "camunda-zeebe-0.camunda-zeebe.c8-snap-cluster-0.svc.cluster.local"},
+			{NodeId: 2, Host: "camunda-zeebe-1.camunda-zeebe.c8-snap-cluster-0.svc.cluster.local"},
+		}},
+		StatefulSetErr: kubectlHelpers.ErrStatefulSetNotFound,
+	}
+}
+
+// topologyOfSize returns a topology of size brokers with the ids 0 to size-1
+func topologyOfSize(size int) kubectlHelpers.ClusterInfo {
+	info := kubectlHelpers.ClusterInfo{ClusterSize: size}
+	for i := 0; i < size; i++ {
+		info.Brokers = append(info.Brokers, kubectlHelpers.Broker{NodeId: i})
+	}
+	return info
+}
+
+// broker returns a broker running in namespace with the given partition replicas
+func broker(nodeId int, namespace string, partitions ...kubectlHelpers.Partition) kubectlHelpers.Broker {
+	return kubectlHelpers.Broker{
+		NodeId:     nodeId,
+		Host:       "camunda-zeebe-0.camunda-zeebe." + namespace + ".svc.cluster.local",
+		Partitions: partitions,
+	}
+}
+
+func leader(id int) kubectlHelpers.Partition {
+	return kubectlHelpers.Partition{PartitionId: id, Role: "leader"}
+}
+
+func follower(id int) kubectlHelpers.Partition {
+	return kubectlHelpers.Partition{PartitionId: id, Role: "follower"}
+}
+
+func followers(ids ...int) []kubectlHelpers.Partition {
+	var partitions []kubectlHelpers.Partition
+	for _, id := range ids {
+		partitions = append(partitions, follower(id))
+	}
+	return partitions
+}
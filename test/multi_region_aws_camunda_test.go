@@ -1,9 +1,8 @@
 package test
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"net/http"
 	"strconv"
 	"strings"
 	"testing"
@@ -11,6 +10,10 @@ import (
 
 	"multiregiontests/internal/helpers"
 	kubectlHelpers "multiregiontests/internal/helpers/kubectl"
+	runbookHelpers "multiregiontests/internal/helpers/runbook"
+	valuesHelpers "multiregiontests/internal/helpers/values"
+	versionHelpers "multiregiontests/internal/helpers/versions"
+	zeebeHelpers "multiregiontests/internal/helpers/zeebe"
 
 	"github.com/gruntwork-io/terratest/modules/k8s"
 	"github.com/stretchr/testify/require"
@@ -19,12 +22,13 @@ import (
 const (
 	remoteChartSource = "https://helm.camunda.io"
 
-	resourceDir         = "../aws/dual-region"
-	terraformDir        = "../aws/dual-region/terraform"
-	kubeConfigPrimary   = "./kubeconfig-london"
-	kubeConfigSecondary = "./kubeconfig-paris"
-	k8sManifests        = "../aws/dual-region/kubernetes"
-	tenantId            = "test-tenant"
+	resourceDir  = "../aws/dual-region"
+	terraformDir = "../aws/dual-region/terraform"
+	k8sManifests = "../aws/dual-region/kubernetes"
+	tenantId     = "test-tenant"
+
+	// failoverRegion is lost and restored by the failover and failback procedures, region 0 keeps serving throughout
+	failoverRegion = 1
 
 	teleportCluster = "camunda.teleport.sh-camunda-ci-eks"
 )
@@ -41,14 +45,27 @@ var (
 	awsProfile         = helpers.GetEnv("AWS_PROFILE", "infraex")
 	migrationOffset, _ = strconv.Atoi(helpers.GetEnv("MIGRATION_OFFSET", "0")) // Offset for process instances started before migration
 
-	primary   helpers.Cluster
-	secondary helpers.Cluster
+	// Operational procedure tweaks
+	deleteOrphanedPVCs  = helpers.GetEnv("DELETE_ORPHANED_PVCS", "false") == "true"       // allows deleting the PVCs of removed brokers when scaling down
+	topologySnapshotDir = helpers.GetEnv("TOPOLOGY_SNAPSHOT_DIR", "./topology_snapshots") // JSON reports of the topology before and after operational steps
+	failbackStateFile   = helpers.GetEnv("FAILBACK_STATE_FILE", "")                       // opt-in checkpoints of the failback, a rerun resumes from the last completed step
+	runbookFile         = helpers.GetEnv("RUNBOOK_FILE", "./runbooks/failover.yml")       // declarative procedure run by TestRunbook
+	runbookDryRun       = helpers.GetEnv("RUNBOOK_DRY_RUN", "false") == "true"            // only logs the steps of the runbook
+
+	// Regions, the Helm values have to match the number of regions and the cluster size
+	regionCount, _       = strconv.Atoi(helpers.GetEnv("REGIONS", "2"))
+	clusterSize, _       = strconv.Atoi(helpers.GetEnv("ZEEBE_CLUSTER_SIZE", "8"))
+	replicationFactor, _ = strconv.Atoi(helpers.GetEnv("ZEEBE_REPLICATION_FACTOR", "4"))
+
+	// clusters is indexed by region id, initialized by initKubernetesHelpers
+	clusters = make(helpers.Clusters, regionCount)
 
-	// Allows setting namespaces via GHA
-	primaryNamespace           = helpers.GetEnv("CLUSTER_0_NAMESPACE", "c8-snap-cluster-0")
-	primaryNamespaceFailover   = helpers.GetEnv("CLUSTER_0_NAMESPACE_FAILOVER", "c8-snap-cluster-0-failover")
-	secondaryNamespace         = helpers.GetEnv("CLUSTER_1_NAMESPACE", "c8-snap-cluster-1")
-	secondaryNamespaceFailover = helpers.GetEnv("CLUSTER_1_NAMESPACE_FAILOVER", "c8-snap-cluster-1-failover")
+	// Default AWS regions indexed by region id, see awsRegion
+	awsRegions = []struct{ Region, Name string }{
+		{"eu-west-2", "london"},
+		{"eu-west-3", "paris"},
+		{"eu-west-1", "ireland"},
+	}
 
 	baseHelmVars = map[string]string{}
 	timeout      = "600s"
@@ -56,17 +73,68 @@ var (
 
 	// Manifest management
 	defaultValuesYaml      = helpers.GetEnv("DEFAULT_VALUES_YAML", "../aws/dual-region/kubernetes/camunda-values.yml")
-	region0ValuesYaml      = helpers.GetEnv("REGION0_VALUES_YAML", "../aws/dual-region/kubernetes/region0/camunda-values.yml")
-	region1ValuesYaml      = helpers.GetEnv("REGION1_VALUES_YAML", "../aws/dual-region/kubernetes/region1/camunda-values.yml")
 	migrationValuesYaml    = helpers.GetEnv("MIGRATION_VALUES_YAML", "../aws/dual-region/kubernetes/camunda-values-migration.yml")
 	multiTenancyValuesYaml = helpers.GetEnv("MULTI_TENANCY_VALUES_YAML", "./fixtures/multi-tenancy.yml")
 	extraValuesYaml        = helpers.GetEnv("EXTRA_VALUES_YAML", "")
 )
 
+// Allows setting namespaces via GHA, CLUSTER_<regionId>_NAMESPACE
+func namespace(region int) string {
+	return helpers.GetEnv(fmt.Sprintf("CLUSTER_%d_NAMESPACE", region), fmt.Sprintf("c8-snap-cluster-%d", region))
+}
+
+// namespaces returns the namespace of every region, known before the clusters are initialized
+func namespaces() []string {
+	names := make([]string, regionCount)
+	for region := range names {
+		names[region] = namespace(region)
+	}
+	return names
+}
+
+func failoverNamespace(region int) string {
+	return helpers.GetEnv(fmt.Sprintf("CLUSTER_%d_NAMESPACE_FAILOVER", region), fmt.Sprintf("c8-snap-cluster-%d-failover", region))
+}
+
+// awsRegion returns the AWS region and its short name hosting a region id
+// Allows overwriting the defaults via CLUSTER_<regionId>_AWS_REGION and CLUSTER_<regionId>_REGION_NAME
+func awsRegion(region int) (string, string) {
+	awsRegionDefault, nameDefault := "", ""
+	if region < len(awsRegions) {
+		awsRegionDefault, nameDefault = awsRegions[region].Region, awsRegions[region].Name
+	}
+	return helpers.GetEnv(fmt.Sprintf("CLUSTER_%d_AWS_REGION", region), awsRegionDefault),
+		helpers.GetEnv(fmt.Sprintf("CLUSTER_%d_REGION_NAME", region), nameDefault)
+}
+
+func kubeConfig(region int) string {
+	_, name := awsRegion(region)
+	return "./kubeconfig-" + name
+}
+
+func kubeConfigs() []string {
+	configs := make([]string, len(clusters))
+	for region := range clusters {
+		configs[region] = kubeConfig(region)
+	}
+	return configs
+}
+
+// regionValuesYaml returns the region specific values file, REGION<regionId>_VALUES_YAML
+// The dual-region example only ships files for region 0 and 1, further regions get their id through InstallUpgradeC8Helm only
+func regionValuesYaml(region int) string {
+	fallback := ""
+	if region < 2 {
+		fallback = fmt.Sprintf("../aws/dual-region/kubernetes/region%d/camunda-values.yml", region)
+	}
+	return helpers.GetEnv(fmt.Sprintf("REGION%d_VALUES_YAML", region), fallback)
+}
+
 // AWS EKS Multi-Region Tests
 
 func TestAWSDeployDualRegCamunda(t *testing.T) {
 	t.Log("[2 REGION TEST] Deploy Camunda 8 in multi region mode 🚀")
+	requireCapabilities(t, versionHelpers.OrchestrationValues)
 
 	if globalImageTag != "" {
 		t.Log("[GLOBAL IMAGE TAG] Overwriting image tag for all Camunda images with " + globalImageTag)
@@ -75,24 +143,30 @@ func TestAWSDeployDualRegCamunda(t *testing.T) {
 	}
 
 	// Runs the tests sequentially
-	for _, testFuncs := range []struct {
-		name  string
-		tfunc func(*testing.T)
-	}{
+	runSteps(t, []testStep{
 		// Camunda 8 Deployment
 		{"TestInitKubernetesHelpers", initKubernetesHelpers},
 		{"TestDeployC8Helm", func(t *testing.T) { deployC8Helm(t, []string{defaultValuesYaml}) }},
 		{"TestCheckC8RunningProperly", checkC8RunningProperly},
+		{"TestCheckPartitionPlacement", checkPartitionPlacement},
 		{"TestDeployC8processAndCheck", func(t *testing.T) { deployC8processAndCheck(t, 6, "default", "") }},
 		{"TestCheckElasticsearchClusterHealth", checkElasticsearchClusterHealth},
 		{"TestCheckTheMath", checkTheMath},
-	} {
-		t.Run(testFuncs.name, testFuncs.tfunc)
+	})
+}
+
+// TestLintValues checks the values of the deployment offline, with the same configuration as the other tests
+func TestLintValues(t *testing.T) {
+	valuesYamlFiles := []string{defaultValuesYaml}
+	if extraValuesYaml != "" {
+		valuesYamlFiles = append(valuesYamlFiles, strings.Split(extraValuesYaml, ",")...)
 	}
+	lintValues(t, valuesYamlFiles)
 }
 
 func TestMigrationDualReg(t *testing.T) {
 	t.Log("[2 REGION TEST] Migrate Camunda 8 in multi region mode 🚀")
+	requireCapabilities(t, versionHelpers.OrchestrationValues, versionHelpers.Migration)
 
 	if globalImageTag != "" {
 		t.Log("[GLOBAL IMAGE TAG] Overwriting image tag for all Camunda images with " + globalImageTag)
@@ -100,28 +174,29 @@ func TestMigrationDualReg(t *testing.T) {
 		baseHelmVars = helpers.OverwriteImageTag(baseHelmVars, globalImageTag)
 	}
 
+	var snapshots *zeebeHelpers.TopologyRecorder
+
 	// Runs the tests sequentially
-	for _, testFuncs := range []struct {
-		name  string
-		tfunc func(*testing.T)
-	}{
+	runSteps(t, []testStep{
 		// Camunda 8 Deployment
 		{"TestInitKubernetesHelpers", initKubernetesHelpers},
+		{"TestInitTopologyRecorder", func(*testing.T) { snapshots = newTopologyRecorder(t, "migration-cleanup") }},
 		{"TestDeployC8Helm", func(t *testing.T) { deployC8Helm(t, []string{migrationValuesYaml}) }},
 		{"TestCheckC8RunningProperly", checkC8RunningProperly},
 		{"TestCheckMigrationSucceed", checkMigrationSucceed},
+		{"TestSnapshotTopologyBeforeCleanup", func(t *testing.T) { snapshots.Snapshot(t, "before cleanup") }},
 		{"TestPostMigrationCleanup", postMigrationCleanup},
+		{"TestSnapshotTopologyAfterCleanup", func(t *testing.T) { snapshots.Snapshot(t, "after cleanup") }},
 		{"TestDeployC8processAndCheck", func(t *testing.T) { deployC8processAndCheck(t, 7, "migration", "") }},
 		{"TestCheckElasticsearchClusterHealth", checkElasticsearchClusterHealth},
 		{"TestCheckTheMath", checkTheMath},
-	} {
-		t.Run(testFuncs.name, testFuncs.tfunc)
-	}
+	})
 }
 
 // Simplified failover procedure for 8.6+
 func TestAWSDualRegFailover_8_6_plus(t *testing.T) {
 	t.Log("[2 REGION TEST] Checking Failover procedure for 8.6+ 🚀")
+	requireCapabilities(t, versionHelpers.OrchestrationValues, versionHelpers.ActuatorCluster, versionHelpers.ActuatorExporters)
 
 	if globalImageTag != "" {
 		t.Log("[GLOBAL IMAGE TAG] Overwriting image tag for all Camunda images with " + globalImageTag)
@@ -129,27 +204,32 @@ func TestAWSDualRegFailover_8_6_plus(t *testing.T) {
 		baseHelmVars = helpers.OverwriteImageTag(baseHelmVars, globalImageTag)
 	}
 
+	var readiness zeebeHelpers.FailoverReadiness
+	var snapshots *zeebeHelpers.TopologyRecorder
+
 	// Runs the tests sequentially
-	for _, testFuncs := range []struct {
-		name  string
-		tfunc func(*testing.T)
-	}{
+	runSteps(t, []testStep{
 		// Multi-Region Operational Procedure
 		// Failover
 		{"TestInitKubernetesHelpers", initKubernetesHelpers},
+		{"TestInitTopologyRecorder", func(*testing.T) { snapshots = newTopologyRecorder(t, "failover") }},
+		{"TestSnapshotTopologyBeforeFailover", func(t *testing.T) { snapshots.Snapshot(t, "before failover") }},
+		{"TestFailoverReadinessReport", func(t *testing.T) { readiness = failoverReadinessReport(t, failoverRegion) }},
 		{"TestDeleteSecondaryRegion", deleteSecondaryRegion},
-		{"TestRemoveSecondaryBrokers", removeSecondaryBrokers},
+		{"TestSnapshotTopologyAfterRegionLoss", func(t *testing.T) { snapshots.Snapshot(t, "after region loss") }},
+		{"TestRemoveSecondaryBrokers", func(t *testing.T) { removeSecondaryBrokers(t, readiness.BrokersToRemove) }},
+		{"TestSnapshotTopologyAfterBrokerRemoval", func(t *testing.T) { snapshots.Snapshot(t, "after broker removal") }},
 		{"TestDisableElasticExportersToSecondary", disableElasticExportersToSecondary},
+		{"TestSnapshotTopologyAfterFailover", func(t *testing.T) { snapshots.Snapshot(t, "after failover") }},
 		{"TestCheckTheMathFailover", checkTheMathFailover_8_6_plus},
 		{"TestDeployC8processAndCheck", func(t *testing.T) { deployC8processAndCheck(t, 12, "failover", "") }},
-	} {
-		t.Run(testFuncs.name, testFuncs.tfunc)
-	}
+	})
 }
 
 // Simplified failback procedure for 8.6+
 func TestAWSDualRegFailback_8_6_plus(t *testing.T) {
 	t.Log("[2 REGION TEST] Running tests for AWS EKS Multi-Region 🚀")
+	requireCapabilities(t, versionHelpers.OrchestrationValues, versionHelpers.ActuatorCluster, versionHelpers.ActuatorExporters)
 
 	if globalImageTag != "" {
 		t.Log("[GLOBAL IMAGE TAG] Overwriting image tag for all Camunda images with " + globalImageTag)
@@ -157,40 +237,71 @@ func TestAWSDualRegFailback_8_6_plus(t *testing.T) {
 		baseHelmVars = helpers.OverwriteImageTag(baseHelmVars, globalImageTag)
 	}
 
-	// Runs the tests sequentially
-	for _, testFuncs := range []struct {
-		name  string
-		tfunc func(*testing.T)
-	}{
-		// Multi-Region Operational Procedure
-		// Failback
-		{"TestInitKubernetesHelpers", initKubernetesHelpers},
-		{"TestRecreateCamundaInSecondary", func(t *testing.T) { redeployWithoutOperateTasklist(t, secondary, true) }},
-		{"TestRedeployCamundaInPrimary", func(t *testing.T) { redeployWithoutOperateTasklist(t, primary, false) }},
-		{"TestCheckC8RunningProperly", checkC8RunningProperly},
-		{"TestStopZeebeExporters", stopZeebeExporters},
-		{"TestCreateElasticBackupRepoPrimary", createElasticBackupRepoPrimary},
-		{"TestCreateElasticBackupPrimary", createElasticBackupPrimary},
-		{"TestCheckThatElasticBackupIsPresentPrimary", checkThatElasticBackupIsPresentPrimary},
-		{"TestCreateElasticBackupRepoSecondary", createElasticBackupRepoSecondary},
-		{"TestCheckThatElasticBackupIsPresentSecondary", checkThatElasticBackupIsPresentSecondary},
-		{"TestRestoreElasticBackupSecondary", restoreElasticBackupSecondary},
-		{"TestCheckElasticsearchClusterHealthAfterRestore", checkElasticsearchClusterHealth},
-		{"TestEnableElasticExportersToSecondary", enableElasticExportersToSecondary},
-		{"TestStartZeebeExporters", startZeebeExporters},
-		{"TestAddSecondaryBrokers", addSecondaryBrokers},
-		{"TestRedeployC8ToEnableOperateTasklist", func(t *testing.T) { deployC8Helm(t, []string{defaultValuesYaml}) }},
-		{"TestCheckC8RunningProperly", checkC8RunningProperly},
-		{"TestDeployC8processAndCheck", func(t *testing.T) { deployC8processAndCheck(t, 18, "default", "") }},
-		{"TestCheckElasticsearchClusterHealthAfterProcessDeploy", checkElasticsearchClusterHealth},
-		{"TestCheckTheMath", checkTheMath},
-	} {
-		t.Run(testFuncs.name, testFuncs.tfunc)
+	var snapshots *zeebeHelpers.TopologyRecorder
+
+	// Multi-Region Operational Procedure
+	// Failback, resumes from the last completed step if a previous run failed
+	helpers.NewProcedure("failback", failbackStateFile, helpers.DeploymentKey(namespaces(), remoteChartVersion),
+		helpers.Step{Name: "TestInitKubernetesHelpers", Always: true, Run: initKubernetesHelpers},
+		helpers.Step{Name: "TestInitTopologyRecorder", Always: true, Run: func(*testing.T) { snapshots = newTopologyRecorder(t, "failback") }},
+		helpers.Step{Name: "TestSnapshotTopologyBeforeFailback", Always: true, Run: func(t *testing.T) { snapshots.Snapshot(t, "before failback") }},
+		helpers.Step{Name: "TestRecreateCamundaInSecondary", Precondition: primaryGatewayReachable, Run: func(t *testing.T) { redeployWithoutOperateTasklist(t, failoverRegion, true) }},
+		helpers.Step{Name: "TestRedeployCamundaInPrimary", Precondition: secondaryBrokersDeployed, Run: func(t *testing.T) { redeployWithoutOperateTasklist(t, 0, false) }},
+		helpers.Step{Name: "TestCheckC8RunningProperly", Always: true, Run: checkC8RunningProperly},
+		helpers.Step{Name: "TestSnapshotTopologyAfterRedeploy", Always: true, Run: func(t *testing.T) { snapshots.Snapshot(t, "after redeploy") }},
+		helpers.Step{Name: "TestStopZeebeExporters", Done: exportingIn(zeebeHelpers.ExporterPhasePaused), Run: stopZeebeExporters},
+		helpers.Step{Name: "TestCreateElasticBackupRepoPrimary", Precondition: requireExportingIn(zeebeHelpers.ExporterPhasePaused), Run: createElasticBackupRepoPrimary},
+		helpers.Step{Name: "TestCreateElasticBackupPrimary", Precondition: requireExportingIn(zeebeHelpers.ExporterPhasePaused), Run: createElasticBackupPrimary},
+		helpers.Step{Name: "TestCheckThatElasticBackupIsPresentPrimary", Run: checkThatElasticBackupIsPresentPrimary},
+		helpers.Step{Name: "TestCreateElasticBackupRepoSecondary", Run: createElasticBackupRepoSecondary},
+		helpers.Step{Name: "TestCheckThatElasticBackupIsPresentSecondary", Run: checkThatElasticBackupIsPresentSecondary},
+		helpers.Step{Name: "TestRestoreElasticBackupSecondary", Precondition: requireExportingIn(zeebeHelpers.ExporterPhasePaused), Run: restoreElasticBackupSecondary},
+		helpers.Step{Name: "TestCheckElasticsearchClusterHealthAfterRestore", Run: checkElasticsearchClusterHealth},
+		helpers.Step{Name: "TestEnableElasticExportersToSecondary", Done: exporterIn(zeebeHelpers.CamundaExporterId(failoverRegion), zeebeHelpers.ExporterStatusEnabled), Precondition: requireExportingIn(zeebeHelpers.ExporterPhasePaused), Run: enableElasticExportersToSecondary},
+		helpers.Step{Name: "TestStartZeebeExporters", Done: exportingIn(zeebeHelpers.ExporterPhaseExporting), Precondition: requireExporterIn(zeebeHelpers.CamundaExporterId(failoverRegion), zeebeHelpers.ExporterStatusEnabled), Run: startZeebeExporters},
+		helpers.Step{Name: "TestSnapshotTopologyAfterExporterRestore", Always: true, Run: func(t *testing.T) { snapshots.Snapshot(t, "after exporter restore") }},
+		helpers.Step{Name: "TestAddSecondaryBrokers", Done: secondaryBrokersJoined, Precondition: requireExportingIn(zeebeHelpers.ExporterPhaseExporting), Run: addSecondaryBrokers},
+		helpers.Step{Name: "TestSnapshotTopologyAfterBrokerAddition", Always: true, Run: func(t *testing.T) { snapshots.Snapshot(t, "after broker addition") }},
+		helpers.Step{Name: "TestRedeployC8ToEnableOperateTasklist", Precondition: requireSecondaryBrokersJoined, Run: func(t *testing.T) { deployC8Helm(t, []string{defaultValuesYaml}) }},
+		helpers.Step{Name: "TestCheckC8RunningProperly", Always: true, Run: checkC8RunningProperly},
+		helpers.Step{Name: "TestSnapshotTopologyAfterFailback", Always: true, Run: func(t *testing.T) { snapshots.Snapshot(t, "after failback") }},
+		helpers.Step{Name: "TestCheckPartitionPlacement", Run: checkPartitionPlacement},
+		helpers.Step{Name: "TestDeployC8process", Done: processInstancesStarted(18, ""), Run: func(t *testing.T) { deployC8process(t, "") }},
+		helpers.Step{Name: "TestCheckC8process", Always: true, Run: func(t *testing.T) { checkC8process(t, 18, "default", "") }},
+		helpers.Step{Name: "TestCheckElasticsearchClusterHealthAfterProcessDeploy", Run: checkElasticsearchClusterHealth},
+		helpers.Step{Name: "TestCheckTheMath", Run: checkTheMath},
+	).Run(t)
+}
+
+// Runs the declarative procedure in RUNBOOK_FILE against both regions
+func TestRunbook(t *testing.T) {
+	t.Log("[2 REGION TEST] Running runbook " + runbookFile + " 🚀")
+	requireCapabilities(t, versionHelpers.OrchestrationValues, versionHelpers.ActuatorCluster)
+
+	if globalImageTag != "" {
+		t.Log("[GLOBAL IMAGE TAG] Overwriting image tag for all Camunda images with " + globalImageTag)
+		// global.image.tag does not overwrite the image tag for all images
+		baseHelmVars = helpers.OverwriteImageTag(baseHelmVars, globalImageTag)
 	}
+
+	runbook, err := runbookHelpers.LoadRunbook(runbookFile)
+	require.NoError(t, err, "[RUNBOOK] Failed to load %s", runbookFile)
+
+	t.Run("TestInitKubernetesHelpers", initKubernetesHelpers)
+
+	runbookHelpers.NewEngine(runbook, runbookHelpers.Environment{
+		Regions:      clusters,
+		RegionOf:     zeebeHelpers.RegionByNamespace(clusters.Namespaces()...),
+		HelmUpgrade:  runbookHelmUpgrade,
+		BackupBucket: backupBucket,
+		ChartVersion: remoteChartVersion,
+		DryRun:       runbookDryRun,
+	}).Run(t)
 }
 
 func TestMultiTenancyDualReg(t *testing.T) {
 	t.Log("[2 REGION TEST] Testing Multi-Tenancy in multi region mode 🚀")
+	requireCapabilities(t, versionHelpers.OrchestrationValues)
 
 	if globalImageTag != "" {
 		t.Log("[GLOBAL IMAGE TAG] Overwriting image tag for all Camunda images with " + globalImageTag)
@@ -199,10 +310,7 @@ func TestMultiTenancyDualReg(t *testing.T) {
 	}
 
 	// Runs the tests sequentially
-	for _, testFuncs := range []struct {
-		name  string
-		tfunc func(*testing.T)
-	}{
+	runSteps(t, []testStep{
 		{"TestInitKubernetesHelpers", initKubernetesHelpers},
 		{"TestDeployC8Helm", func(t *testing.T) { deployC8Helm(t, []string{defaultValuesYaml, multiTenancyValuesYaml}) }},
 		{"TestCheckC8RunningProperly", checkC8RunningProperly},
@@ -211,76 +319,75 @@ func TestMultiTenancyDualReg(t *testing.T) {
 		{"TestCheckTenantExists", checkTenantExists},
 		{"ResetMigrationOffset", func(t *testing.T) { migrationOffset = 0 }}, // in case the migration job runs this, the tenant has no previous history
 		{"TestDeployC8processAndCheckWithTenant", func(t *testing.T) { deployC8processAndCheck(t, 6, "default", tenantId) }},
-	} {
-		t.Run(testFuncs.name, testFuncs.tfunc)
-	}
+	})
 }
 
 func TestDebugStep(t *testing.T) {
 	t.Log("[DEBUG] Debugging step 🚀")
 
 	// Runs the tests sequentially
-	for _, testFuncs := range []struct {
-		name  string
-		tfunc func(*testing.T)
-	}{
+	runSteps(t, []testStep{
 		{"TestInitKubernetesHelpers", initKubernetesHelpers},
 		{"TestDebugStep", debugStep},
-	} {
-		t.Run(testFuncs.name, testFuncs.tfunc)
-	}
+	})
 }
 
 func TestAWSDualRegCleanup(t *testing.T) {
 	t.Log("[2 REGION TEST] Cleaning up the environment 🚀")
 
 	// Runs the tests sequentially
-	for _, testFuncs := range []struct {
-		name  string
-		tfunc func(*testing.T)
-	}{
+	runSteps(t, []testStep{
 		{"TestInitKubernetesHelpers", initKubernetesHelpers},
 		{"TestTeardownAllC8Helm", teardownAllC8Helm},
-	} {
-		t.Run(testFuncs.name, testFuncs.tfunc)
-	}
+	})
 }
 
 // Single Test functions
 
+// newTopologyRecorder records the topology of an operation through the gateway of region 0, once the clusters are initialized
+// t is the test of the whole operation, a snapshot is taken once it failed
+func newTopologyRecorder(t *testing.T, operation string) *zeebeHelpers.TopologyRecorder {
+	recorder := zeebeHelpers.NewTopologyRecorder(&clusters[0].KubectlNamespace, operation, topologySnapshotDir)
+	recorder.SnapshotOnFailure(t)
+	return recorder
+}
+
 func initKubernetesHelpers(t *testing.T) {
+	require.GreaterOrEqual(t, regionCount, 2, "[K8S INIT] A multi-region setup needs at least 2 regions")
 
 	if helpers.IsTeleportEnabled() {
-		t.Log("[K8S INIT] Initializing Kubernetes helpers with Teleport 🚀")
-		primary = helpers.Cluster{
-			Region:           "eu-west-2",
-			ClusterName:      teleportCluster,
-			KubectlNamespace: *k8s.NewKubectlOptions("", "kubeconfig", primaryNamespace),
-			KubectlFailover:  *k8s.NewKubectlOptions("", "kubeconfig", primaryNamespaceFailover),
-		}
-		secondary = helpers.Cluster{
-			Region:           "eu-west-3",
-			ClusterName:      teleportCluster,
-			KubectlNamespace: *k8s.NewKubectlOptions("", "kubeconfig", secondaryNamespace),
-			KubectlFailover:  *k8s.NewKubectlOptions("", "kubeconfig", secondaryNamespaceFailover),
-		}
+		t.Logf("[K8S INIT] Initializing Kubernetes helpers for %d regions with Teleport 🚀", regionCount)
 	} else {
-		t.Log("[K8S INIT] Initializing Kubernetes helpers 🚀")
-		primary = helpers.Cluster{
-			Region:           "eu-west-2",
-			ClusterName:      fmt.Sprintf("%s-london", clusterName),
-			KubectlNamespace: *k8s.NewKubectlOptions("", kubeConfigPrimary, primaryNamespace),
-			KubectlSystem:    *k8s.NewKubectlOptions("", kubeConfigPrimary, "kube-system"),
-			KubectlFailover:  *k8s.NewKubectlOptions("", kubeConfigPrimary, primaryNamespaceFailover),
-		}
-		secondary = helpers.Cluster{
-			Region:           "eu-west-3",
-			ClusterName:      fmt.Sprintf("%s-paris", clusterName),
-			KubectlNamespace: *k8s.NewKubectlOptions("", kubeConfigSecondary, secondaryNamespace),
-			KubectlSystem:    *k8s.NewKubectlOptions("", kubeConfigSecondary, "kube-system"),
-			KubectlFailover:  *k8s.NewKubectlOptions("", kubeConfigSecondary, secondaryNamespaceFailover),
+		t.Logf("[K8S INIT] Initializing Kubernetes helpers for %d regions 🚀", regionCount)
+	}
+
+	for region := range clusters {
+		cloudRegion, name := awsRegion(region)
+		require.NotEmpty(t, cloudRegion, "[K8S INIT] No AWS region configured for region %d, set CLUSTER_%d_AWS_REGION", region, region)
+		require.NotEmpty(t, name, "[K8S INIT] No name configured for region %d, set CLUSTER_%d_REGION_NAME", region, region)
+
+		if helpers.IsTeleportEnabled() {
+			clusters[region] = helpers.Cluster{
+				Region:           cloudRegion,
+				ClusterName:      teleportCluster,
+				KubectlNamespace: *k8s.NewKubectlOptions("", "kubeconfig", namespace(region)),
+				KubectlFailover:  *k8s.NewKubectlOptions("", "kubeconfig", failoverNamespace(region)),
+				RegionID:         region,
+				ValuesFile:       regionValuesYaml(region),
+			}
+		} else {
+			clusters[region] = helpers.Cluster{
+				Region:           cloudRegion,
+				ClusterName:      fmt.Sprintf("%s-%s", clusterName, name),
+				KubectlNamespace: *k8s.NewKubectlOptions("", kubeConfig(region), namespace(region)),
+				KubectlSystem:    *k8s.NewKubectlOptions("", kubeConfig(region), "kube-system"),
+				KubectlFailover:  *k8s.NewKubectlOptions("", kubeConfig(region), failoverNamespace(region)),
+				RegionID:         region,
+				ValuesFile:       regionValuesYaml(region),
+			}
 		}
 	}
+	require.NoError(t, clusters.Validate())
 }
 
 func deployC8Helm(t *testing.T, valuesYamlFiles []string) {
@@ -304,52 +411,124 @@ func deployC8Helm(t *testing.T, valuesYamlFiles []string) {
 		valuesYamlFiles = append(valuesYamlFiles, extraValuesYamls...)
 	}
 
-	// We have to install both at the same time as otherwise zeebe will not become ready
-	kubectlHelpers.InstallUpgradeC8Helm(t, &primary.KubectlNamespace, remoteChartVersion, remoteChartName, remoteChartSource, primaryNamespace, secondaryNamespace, append(valuesYamlFiles, region0ValuesYaml), 0, baseHelmVars, setStringValues)
+	lintValues(t, valuesYamlFiles)
+
+	// We have to install all regions at the same time as otherwise zeebe will not become ready
+	releases := make([]kubectlHelpers.C8HelmRelease, len(clusters))
+	for region := range clusters {
+		releases[region] = kubectlHelpers.PrepareC8Helm(t, clusters[region], remoteChartVersion, remoteChartName, remoteChartSource, clusters.Namespaces(), clusterSize, valuesYamlFiles, baseHelmVars, setStringValues)
+	}
+
+	// Elastic itself takes already ~2+ minutes to start, connectors come last as they depend on the Orchestration Cluster
+	upgradeC8HelmAtomically(t, releases, []string{
+		"statefulset/camunda-elasticsearch-master",
+		"statefulset/camunda-zeebe",
+		"deployment/camunda-connectors",
+	}, checkC8RunningProperly)
+}
+
+// upgradeC8HelmAtomically upgrades the prepared releases and rolls all of them back to their previous revision if one fails
+// verify checks the health after a rollback, nil where the regions can't be healthy on their own, e.g. in the middle of a failback
+func upgradeC8HelmAtomically(t *testing.T, releases []kubectlHelpers.C8HelmRelease, workloads []string, verify func(t *testing.T)) {
+	rolloutTimeout, err := time.ParseDuration(timeout)
+	require.NoError(t, err)
+	kubectlHelpers.UpgradeC8HelmAtomically(t, releases, workloads, rolloutTimeout+time.Duration(retries)*15*time.Second, 15*time.Second, verify)
+}
 
-	kubectlHelpers.InstallUpgradeC8Helm(t, &secondary.KubectlNamespace, remoteChartVersion, remoteChartName, remoteChartSource, primaryNamespace, secondaryNamespace, append(valuesYamlFiles, region1ValuesYaml), 1, baseHelmVars, setStringValues)
+// requireCapabilities skips the test if the Camunda version installed by HELM_CHART_VERSION lacks one of the capabilities
+func requireCapabilities(t *testing.T, capabilities ...versionHelpers.Capability) {
+	chart, err := versionHelpers.ForChart(remoteChartVersion)
+	require.NoError(t, err, "[VERSION] Unknown chart version")
+	chart.Require(t, capabilities...)
+}
 
-	// Check that all deployments and Statefulsets are available
-	// Terratest has no direct function for Statefulsets, therefore defaulting to pods directly
+// lintValues checks the multi-region invariants of the values stack of all regions before Helm sees it
+func lintValues(t *testing.T, valuesYamlFiles []string) {
+	stack := valuesHelpers.Stack{
+		Common: valuesYamlFiles,
+		Config: &valuesHelpers.Config{Release: "camunda", ClusterSize: clusterSize},
+	}
+	for region := range regionCount {
+		stack.Regions = append(stack.Regions, regionValuesYaml(region))
+		stack.Config.Namespaces = append(stack.Config.Namespaces, namespace(region))
+	}
+	require.NoError(t, stack.Lint(), "[C8 HELM] The values are not valid for %d regions", regionCount)
+}
 
-	k8s.RunKubectl(t, &primary.KubectlNamespace, "get", "pods")
-	k8s.RunKubectl(t, &secondary.KubectlNamespace, "get", "pods")
+// runbookHelmUpgrade upgrades a single region with the same base values and region values as deployC8Helm
+func runbookHelmUpgrade(ctx context.Context, t *testing.T, region int, valuesFiles []string, setValues, setStringValues map[string]string) {
+	t.Logf("[C8 HELM] Upgrading Camunda Platform Helm Chart in region %d 🚀", region)
 
-	// Elastic itself takes already ~2+ minutes to start
-	// no functions for Statefulsets yet
-	k8s.RunKubectl(t, &primary.KubectlNamespace, "rollout", "status", "--watch", "--timeout="+timeout, "statefulset/camunda-elasticsearch-master")
-	k8s.RunKubectl(t, &primary.KubectlNamespace, "rollout", "status", "--watch", "--timeout="+timeout, "statefulset/camunda-zeebe")
+	// avoid pod anti-affinity limitations
+	baseHelmVars["orchestration.affinity.podAntiAffinity"] = "null"
 
-	// no functions for Statefulsets yet
-	k8s.RunKubectl(t, &secondary.KubectlNamespace, "rollout", "status", "--watch", "--timeout="+timeout, "statefulset/camunda-elasticsearch-master")
-	k8s.RunKubectl(t, &secondary.KubectlNamespace, "rollout", "status", "--watch", "--timeout="+timeout, "statefulset/camunda-zeebe")
+	valuesYamlFiles := append([]string{defaultValuesYaml}, valuesFiles...)
+	if extraValuesYaml != "" {
+		valuesYamlFiles = append(valuesYamlFiles, strings.Split(extraValuesYaml, ",")...)
+	}
 
-	// connectors last as they depend on the Orchestration Cluster
-	k8s.WaitUntilDeploymentAvailable(t, &primary.KubectlNamespace, "camunda-connectors", retries, 15*time.Second)
+	// The runbook waits for the brokers itself, they may not be able to join at this point
+	release := kubectlHelpers.PrepareC8Helm(t, clusters[region], remoteChartVersion, remoteChartName, remoteChartSource, clusters.Namespaces(), clusterSize,
+		valuesYamlFiles, helpers.CombineMaps(baseHelmVars, setValues), setStringValues)
+	deadline, _ := ctx.Deadline()
+	kubectlHelpers.UpgradeC8HelmAtomically(t, []kubectlHelpers.C8HelmRelease{release}, []string{"statefulset/camunda-elasticsearch-master"}, time.Until(deadline), 15*time.Second, nil)
 }
 
 func checkC8RunningProperly(t *testing.T) {
 	t.Log("[C8 CHECK] Checking if Camunda Platform is running properly 🚦")
-	kubectlHelpers.CheckC8RunningProperly(t, primary, primaryNamespace, secondaryNamespace)
+	kubectlHelpers.CheckC8RunningProperly(t, clusters[0], clusters.Namespaces(), clusterSize)
+}
+
+// checkPartitionPlacement verifies that every partition is replicated to every region
+func checkPartitionPlacement(t *testing.T) {
+	t.Log("[PARTITION PLACEMENT] Checking partition replicas are spread across regions 🔍")
+
+	topology := kubectlHelpers.GetClusterTopology(t, &clusters[0].KubectlNamespace)
+	report := zeebeHelpers.AnalyzePlacement(topology, regionCount, zeebeHelpers.RegionByNamespace(clusters.Namespaces()...))
+	zeebeHelpers.RequireReplicasInEveryRegion(t, report)
 }
 
 func deployC8processAndCheck(t *testing.T, expectedProcesses int, mode, tenantId string) {
-	t.Log("[C8 PROCESS] Deploying a process and checking if it's running 🚀")
+	deployC8process(t, tenantId)
+	checkC8process(t, expectedProcesses, mode, tenantId)
+}
 
-	tmpExpectedProcesses := expectedProcesses + migrationOffset
+// deployC8process deploys the process and starts its instances in region 0
+func deployC8process(t *testing.T, tenantId string) {
+	t.Log("[C8 PROCESS] Deploying a process and starting instances 🚀")
+	kubectlHelpers.DeployC8processAndCheck(t, clusters[0], resourceDir, tenantId)
+}
+
+// processInstancesStarted reports whether the instances of deployC8process are already there
+func processInstancesStarted(expectedProcesses int, tenantId string) func(t *testing.T) bool {
+	return func(t *testing.T) bool {
+		count, err := kubectlHelpers.CountProcessInstancesE(t, clusters[0], tenantId)
+		if err != nil {
+			t.Logf("[C8 PROCESS] Can't count the process instances: %v", err)
+			return false
+		}
+		return count >= expectedProcesses+migrationOffset
+	}
+}
 
-	kubectlHelpers.DeployC8processAndCheck(t, primary, resourceDir, tenantId)
+// checkC8process verifies the process and the expected number of instances are visible in every region
+func checkC8process(t *testing.T, expectedProcesses int, mode, tenantId string) {
+	t.Log("[C8 PROCESS] Checking if the process is running 🔍")
 
-	kubectlHelpers.CheckOperateForProcesses(t, primary, tenantId)
+	tmpExpectedProcesses := expectedProcesses + migrationOffset
 
-	if mode != "failover" {
-		kubectlHelpers.CheckOperateForProcesses(t, secondary, tenantId)
+	// the failover region is gone after a failover
+	regions := clusters.RegionIds()
+	if mode == "failover" {
+		regions = clusters.RegionIds(failoverRegion)
 	}
 
-	kubectlHelpers.CheckOperateForProcessInstances(t, primary, tmpExpectedProcesses, tenantId)
+	for _, region := range regions {
+		kubectlHelpers.CheckOperateForProcesses(t, clusters[region], tenantId)
+	}
 
-	if mode != "failover" {
-		kubectlHelpers.CheckOperateForProcessInstances(t, secondary, tmpExpectedProcesses, tenantId)
+	for _, region := range regions {
+		kubectlHelpers.CheckOperateForProcessInstances(t, clusters[region], tmpExpectedProcesses, tenantId)
 	}
 }
 
@@ -359,12 +538,12 @@ func createTestTenant(t *testing.T) {
 	name := "Test Tenant"
 	description := "A test tenant for multi-region testing"
 
-	// Create tenant in primary cluster
-	kubectlHelpers.CreateTenant(t, primary, tenantId, name, description)
+	// Create tenant in the cluster of region 0
+	kubectlHelpers.CreateTenant(t, clusters[0], tenantId, name, description)
 
 	// Assign admin role to the tenant
 	t.Log("[TENANT] Assigning admin role to tenant")
-	kubectlHelpers.AssignRoleToTenant(t, primary, tenantId, "admin")
+	kubectlHelpers.AssignRoleToTenant(t, clusters[0], tenantId, "admin")
 
 	// Wait a moment for tenant to be propagated
 	t.Log("[TENANT] Waiting for tenant to be propagated...")
@@ -374,39 +553,36 @@ func createTestTenant(t *testing.T) {
 func checkTenantExists(t *testing.T) {
 	t.Log("[TENANT] Checking if tenant exists 🔍")
 
-	// Check tenant exists in primary cluster
-	kubectlHelpers.CheckTenantExists(t, primary, tenantId)
-
-	// Check tenant exists in secondary cluster
-	kubectlHelpers.CheckTenantExists(t, secondary, tenantId)
+	// Check tenant exists in every region
+	for _, cluster := range clusters {
+		kubectlHelpers.CheckTenantExists(t, cluster, tenantId)
+	}
 }
 
 func teardownAllC8Helm(t *testing.T) {
 	t.Log("[C8 HELM TEARDOWN] Tearing down Camunda Platform Helm Chart 🚀")
-	kubectlHelpers.TeardownC8Helm(t, &primary.KubectlNamespace)
-	kubectlHelpers.TeardownC8Helm(t, &secondary.KubectlNamespace)
+	for region := range clusters {
+		kubectlHelpers.TeardownC8Helm(t, &clusters[region].KubectlNamespace)
+	}
 }
 
 func debugStep(t *testing.T) {
 	t.Log("[DEBUG] Debugging step 🚀")
 
-	t.Log("[DEBUG] Running kubectl get pods")
+	for region := range clusters {
+		options := &clusters[region].KubectlNamespace
 
-	k8s.RunKubectl(t, &primary.KubectlNamespace, "get", "pods")
-	k8s.RunKubectl(t, &secondary.KubectlNamespace, "get", "pods")
+		t.Logf("[DEBUG] Running kubectl get pods in region %d", region)
+		k8s.RunKubectl(t, options, "get", "pods")
 
-	t.Log("[DEBUG] Running kubectl describe pods")
+		t.Logf("[DEBUG] Running kubectl describe pods in region %d", region)
+		k8s.RunKubectl(t, options, "describe", "pods")
 
-	k8s.RunKubectl(t, &primary.KubectlNamespace, "describe", "pods")
-	k8s.RunKubectl(t, &secondary.KubectlNamespace, "describe", "pods")
+		t.Logf("[DEBUG] Running kubectl describe configmaps in region %d", region)
+		k8s.RunKubectl(t, options, "describe", "configmaps")
 
-	t.Log("[DEBUG] Running kubectl describe configmaps")
-
-	k8s.RunKubectl(t, &primary.KubectlNamespace, "describe", "configmaps")
-	k8s.RunKubectl(t, &secondary.KubectlNamespace, "describe", "configmaps")
-
-	kubectlHelpers.DumpAllPodLogs(t, &primary.KubectlNamespace)
-	kubectlHelpers.DumpAllPodLogs(t, &secondary.KubectlNamespace)
+		kubectlHelpers.DumpAllPodLogs(t, options)
+	}
 }
 
 // Multi-Region Operational Procedure Additions
@@ -416,64 +592,59 @@ func debugStep(t *testing.T) {
 func createElasticBackupRepoPrimary(t *testing.T) {
 	t.Log("[ELASTICSEARCH] Creating Elasticsearch Backup Repository 🚀")
 
-	kubectlHelpers.ConfigureElasticBackup(t, primary, backupBucket, remoteChartVersion)
+	kubectlHelpers.ConfigureElasticBackup(t, clusters[0], backupBucket, remoteChartVersion)
 }
 
 func createElasticBackupPrimary(t *testing.T) {
 	t.Log("[ELASTICSEARCH BACKUP] Creating Elasticsearch Backup 🚀")
 
-	kubectlHelpers.CreateElasticBackup(t, primary, backupName)
+	kubectlHelpers.CreateElasticBackup(t, clusters[0], backupName)
 }
 
 func checkThatElasticBackupIsPresentPrimary(t *testing.T) {
 	t.Log("[ELASTICSEARCH BACKUP] Checking if Elasticsearch Backup is present 🚀")
 
-	kubectlHelpers.CheckThatElasticBackupIsPresent(t, primary, backupName, backupBucket, remoteChartVersion)
+	kubectlHelpers.CheckThatElasticBackupIsPresent(t, clusters[0], backupName, backupBucket, remoteChartVersion)
 }
 
 func createElasticBackupRepoSecondary(t *testing.T) {
 	t.Log("[ELASTICSEARCH] Creating Elasticsearch Backup Repository 🚀")
 
-	kubectlHelpers.ConfigureElasticBackup(t, secondary, backupBucket, remoteChartVersion)
+	kubectlHelpers.ConfigureElasticBackup(t, clusters[failoverRegion], backupBucket, remoteChartVersion)
 }
 
 func checkThatElasticBackupIsPresentSecondary(t *testing.T) {
 	t.Log("[ELASTICSEARCH BACKUP] Checking if Elasticsearch Backup is present 🚀")
 
-	kubectlHelpers.CheckThatElasticBackupIsPresent(t, secondary, backupName, backupBucket, remoteChartVersion)
+	kubectlHelpers.CheckThatElasticBackupIsPresent(t, clusters[failoverRegion], backupName, backupBucket, remoteChartVersion)
 }
 
 func restoreElasticBackupSecondary(t *testing.T) {
 	t.Log("[ELASTICSEARCH BACKUP] Restoring Elasticsearch Backup 🚀")
 
-	kubectlHelpers.RestoreElasticBackup(t, secondary, backupName)
+	kubectlHelpers.RestoreElasticBackup(t, clusters[failoverRegion], backupName)
 }
 
 func checkElasticsearchClusterHealth(t *testing.T) {
-	t.Log("[ELASTICSEARCH HEALTH] Checking cluster health in both regions 🚀")
+	t.Log("[ELASTICSEARCH HEALTH] Checking cluster health in all regions 🚀")
 
-	kubectlHelpers.CheckElasticsearchClusterHealth(t, primary)
-	kubectlHelpers.CheckElasticsearchClusterHealth(t, secondary)
+	for _, cluster := range clusters {
+		kubectlHelpers.CheckElasticsearchClusterHealth(t, cluster)
+	}
 }
 
 func deleteSecondaryRegion(t *testing.T) {
 	t.Log("[REGION REMOVAL] Deleting secondary region 🚀")
 
-	kubectlHelpers.TeardownC8Helm(t, &secondary.KubectlNamespace)
+	kubectlHelpers.TeardownC8Helm(t, &clusters[failoverRegion].KubectlNamespace)
 }
 
-// redeployWithoutOperateTasklist redeploys Camunda in the specified cluster with Operate and Tasklist disabled.
-// For secondary cluster, it also disables schema creation to prevent conflicts during DB restore.
-func redeployWithoutOperateTasklist(t *testing.T, cluster helpers.Cluster, disableSchemaCreation bool) {
+// redeployWithoutOperateTasklist redeploys Camunda in the cluster of the region with Operate and Tasklist disabled.
+// For the failover region, it also disables schema creation to prevent conflicts during DB restore.
+func redeployWithoutOperateTasklist(t *testing.T, region int, disableSchemaCreation bool) {
+	cluster := clusters[region]
 	t.Logf("[C8 HELM] Redeploying Camunda Platform Helm Chart in %s 🚀", cluster.ClusterName)
 
-	region := 0
-
-	// assumption: eu-west-2 = 0 and eu-west-3 = 1
-	if cluster.Region == "eu-west-3" {
-		region = 1
-	}
-
 	setValues := map[string]string{}
 	setStringValues := map[string]string{}
 
@@ -482,15 +653,12 @@ func redeployWithoutOperateTasklist(t *testing.T, cluster helpers.Cluster, disab
 		baseHelmVars["orchestration.affinity.podAntiAffinity"] = "null"
 	}
 
-	// We have to disable Operate and Tasklist due to better UX + risk of data loss in case of local actions
-	setValues["orchestration.profiles.operate"] = "false"
-	setValues["orchestration.profiles.tasklist"] = "false"
+	chart, err := versionHelpers.ForChart(remoteChartVersion)
+	require.NoError(t, err, "[VERSION] Unknown chart version")
 
-	// Disable schema creation if requested (needed for secondary during DB restore)
-	if disableSchemaCreation {
-		setStringValues["orchestration.env[12].name"] = "CAMUNDA_DATABASE_SCHEMAMANAGER_CREATESCHEMA"
-		setStringValues["orchestration.env[12].value"] = "false"
-	}
+	// We have to disable Operate and Tasklist due to better UX + risk of data loss in case of local actions
+	setValues[chart.ProfileKey("operate")] = "false"
+	setValues[chart.ProfileKey("tasklist")] = "false"
 
 	valuesYamlFiles := []string{defaultValuesYaml}
 
@@ -499,380 +667,289 @@ func redeployWithoutOperateTasklist(t *testing.T, cluster helpers.Cluster, disab
 		valuesYamlFiles = append(valuesYamlFiles, extraValuesYamls...)
 	}
 
-	if region == 0 {
-		valuesYamlFiles = append(valuesYamlFiles, region0ValuesYaml)
-	} else {
-		valuesYamlFiles = append(valuesYamlFiles, region1ValuesYaml)
+	// Disable schema creation if requested (needed for secondary during DB restore)
+	// The overlay carries the whole env with the entry merged in by name
+	if disableSchemaCreation {
+		values := valuesHelpers.NewBuilder()
+		require.NoError(t, values.LoadFiles(valuesYamlFiles...))
+		if cluster.ValuesFile != "" {
+			require.NoError(t, values.LoadFiles(cluster.ValuesFile))
+		}
+		require.NoError(t, values.SetEnv(valuesHelpers.EnvVar{Name: "CAMUNDA_DATABASE_SCHEMAMANAGER_CREATESCHEMA", Value: "false"}))
+		overlay, err := values.RenderOverlay(valuesHelpers.EnvPath)
+		require.NoError(t, err)
+		overlayFile, err := helpers.RenderOverlay(t, "schema-creation-disabled.yml", string(overlay))
+		require.NoError(t, err)
+		valuesYamlFiles = append(valuesYamlFiles, overlayFile)
 	}
 
-	kubectlHelpers.InstallUpgradeC8Helm(t, &cluster.KubectlNamespace, remoteChartVersion, remoteChartName, remoteChartSource, primaryNamespace, secondaryNamespace, valuesYamlFiles, region, helpers.CombineMaps(baseHelmVars, setValues), setStringValues)
-
-	k8s.RunKubectl(t, &cluster.KubectlNamespace, "rollout", "status", "--watch", "--timeout="+timeout, "statefulset/camunda-elasticsearch-master")
+	lintValues(t, valuesYamlFiles)
 
 	// We can't wait for Zeebe to become ready as it's not part of the cluster, therefore out of service 503
 	// We are using instead elastic to become ready as the next steps depend on it, additionally as direct next step we check that the brokers have joined in again.
-	// We skip this for region 1 since only region 0 is part of the cluster at this point.
-	if region == 0 {
-		k8s.RunKubectl(t, &cluster.KubectlNamespace, "rollout", "status", "--watch", "--timeout="+timeout, "statefulset/camunda-zeebe")
+	// We skip this for the failover region since its brokers are not part of the cluster at this point.
+	workloads := []string{"statefulset/camunda-elasticsearch-master"}
+	if cluster.RegionID != failoverRegion {
+		workloads = append(workloads, "statefulset/camunda-zeebe")
 	}
+
+	// The other region is not complete during the failback, so a rollback can't be verified by the topology
+	release := kubectlHelpers.PrepareC8Helm(t, cluster, remoteChartVersion, remoteChartName, remoteChartSource, clusters.Namespaces(), clusterSize, valuesYamlFiles, helpers.CombineMaps(baseHelmVars, setValues), setStringValues)
+	upgradeC8HelmAtomically(t, []kubectlHelpers.C8HelmRelease{release}, workloads, nil)
 }
 
+// stopZeebeExporters hard pauses exporting, the Elasticsearch backup must only be taken once all partitions confirm the pause
 func stopZeebeExporters(t *testing.T) {
 	t.Log("[ZEEBE EXPORTERS] Stopping Zeebe Exporters 🚀")
 
-	var output string
-	var err error
-
-	// Partition distribution may take a while and results in a 500 error
-	for i := 0; i < 10; i++ {
-		output, err = k8s.RunKubectlAndGetOutputE(t, &primary.KubectlNamespace, "exec", "camunda-elasticsearch-master-0", "--", "curl", "-i", "camunda-zeebe-gateway:9600/actuator/exporting/pause", "-XPOST")
-		if err != nil {
-			t.Fatalf("[ZEEBE EXPORTERS] Failed to pause exporters: %v", err)
-			return
-		}
-
-		if strings.Contains(output, "HTTP/1.1 20") {
-			t.Log("[ZEEBE EXPORTERS] Output contains a 20x response")
-			break
-		}
-		t.Log("[ZEEBE EXPORTERS] Pausing exporters failed, retrying...")
-		time.Sleep(30 * time.Second)
-	}
-
-	require.Contains(t, output, "HTTP/1.1 20")
-	t.Logf("[ZEEBE EXPORTERS] Paused exporters: %s", output)
+	zeebeHelpers.NewExportingControl(&clusters[0].KubectlNamespace, clusters.KubectlNamespaces()...).Pause(t, false)
 }
 
 func startZeebeExporters(t *testing.T) {
 	t.Log("[ZEEBE EXPORTERS] Starting Zeebe Exporters 🚀")
 
-	var output string
-	var err error
-
-	// Partition distribution may take a while and results in a 500 error
-	for i := 0; i < 10; i++ {
-		output, err = k8s.RunKubectlAndGetOutputE(t, &primary.KubectlNamespace, "exec", "camunda-elasticsearch-master-0", "--", "curl", "-i", "camunda-zeebe-gateway:9600/actuator/exporting/resume", "-XPOST")
-		if err != nil {
-			t.Fatalf("[ZEEBE EXPORTERS] Failed to resume exporters: %v", err)
-			return
-		}
-
-		if strings.Contains(output, "HTTP/1.1 20") {
-			t.Log("[ZEEBE EXPORTERS] Output contains a 20x response")
-			break
-		}
-		t.Log("[ZEEBE EXPORTERS] Resuming exporters failed, retrying...")
-		time.Sleep(30 * time.Second)
-	}
-
-	require.Contains(t, output, "HTTP/1.1 20")
-	t.Logf("[ZEEBE EXPORTERS] Resumed exporters: %s", output)
+	zeebeHelpers.NewExportingControl(&clusters[0].KubectlNamespace, clusters.KubectlNamespaces()...).Resume(t)
 }
 
 func checkTheMath(t *testing.T) {
 	t.Log("[MATH] Checking the math 🚀")
 
-	t.Log("[MATH] Checking if the primary deployment has even broker IDs")
-	require.True(t, helpers.IsEven(kubectlHelpers.GetZeebeBrokerId(t, &primary.KubectlNamespace, "camunda-zeebe-0")))
-	require.True(t, helpers.IsEven(kubectlHelpers.GetZeebeBrokerId(t, &primary.KubectlNamespace, "camunda-zeebe-1")))
-	require.True(t, helpers.IsEven(kubectlHelpers.GetZeebeBrokerId(t, &primary.KubectlNamespace, "camunda-zeebe-2")))
-	require.True(t, helpers.IsEven(kubectlHelpers.GetZeebeBrokerId(t, &primary.KubectlNamespace, "camunda-zeebe-3")))
-
-	t.Log("[MATH] Checking if the secondary deployment has odd broker IDs")
-	require.True(t, helpers.IsOdd(kubectlHelpers.GetZeebeBrokerId(t, &secondary.KubectlNamespace, "camunda-zeebe-0")))
-	require.True(t, helpers.IsOdd(kubectlHelpers.GetZeebeBrokerId(t, &secondary.KubectlNamespace, "camunda-zeebe-1")))
-	require.True(t, helpers.IsOdd(kubectlHelpers.GetZeebeBrokerId(t, &secondary.KubectlNamespace, "camunda-zeebe-2")))
-	require.True(t, helpers.IsOdd(kubectlHelpers.GetZeebeBrokerId(t, &secondary.KubectlNamespace, "camunda-zeebe-3")))
+	t.Logf("[MATH] Checking if every broker has the node id podIndex * %d + regionId", regionCount)
+	topology := kubectlHelpers.GetClusterTopology(t, &clusters[0].KubectlNamespace)
+	identities := zeebeHelpers.ResolveBrokerIdentities(t, topology, clusters.KubectlNamespaces()...)
+	zeebeHelpers.RequireNodeIdScheme(t, identities, regionCount)
 }
 
 func checkTheMathFailover_8_6_plus(t *testing.T) {
 	t.Log("[MATH] Checking the math for Failover 🚀")
 
-	t.Log("[MATH] Checking if the primary deployment has even broker IDs")
-	require.True(t, helpers.IsEven(kubectlHelpers.GetZeebeBrokerId(t, &primary.KubectlNamespace, "camunda-zeebe-0")))
-	require.True(t, helpers.IsEven(kubectlHelpers.GetZeebeBrokerId(t, &primary.KubectlNamespace, "camunda-zeebe-1")))
-	require.True(t, helpers.IsEven(kubectlHelpers.GetZeebeBrokerId(t, &primary.KubectlNamespace, "camunda-zeebe-2")))
-	require.True(t, helpers.IsEven(kubectlHelpers.GetZeebeBrokerId(t, &primary.KubectlNamespace, "camunda-zeebe-3")))
+	t.Logf("[MATH] Checking if the brokers of the surviving regions have the node id podIndex * %d + regionId", regionCount)
+	topology := kubectlHelpers.GetClusterTopology(t, &clusters[0].KubectlNamespace)
+	surviving := clusters.KubectlNamespaces()
+	surviving[failoverRegion] = nil
+	identities := zeebeHelpers.ResolveBrokerIdentities(t, topology, surviving...)
+	zeebeHelpers.RequireNodeIdScheme(t, identities, regionCount)
 }
 
-func removeSecondaryBrokers(t *testing.T) {
-	t.Log("[FAILOVER] Removing secondary brokers 🚀")
-	service := k8s.GetService(t, &primary.KubectlNamespace, "camunda-zeebe-gateway")
-	require.Equal(t, service.Name, "camunda-zeebe-gateway")
+// failoverReadinessReport simulates the loss of the region on the live topology before anything is removed
+func failoverReadinessReport(t *testing.T, region int) zeebeHelpers.FailoverReadiness {
+	t.Logf("[FAILOVER READINESS] Checking what losing region %d means for the cluster 🔍", region)
+
+	topology := kubectlHelpers.GetClusterTopology(t, &clusters[0].KubectlNamespace)
+	return zeebeHelpers.ReportFailoverReadiness(t, topology, regionCount, zeebeHelpers.RegionByNamespace(clusters.Namespaces()...), region)
+}
 
-	endpoint, closeFn := kubectlHelpers.NewServiceTunnelWithRetry(t, &primary.KubectlNamespace, "camunda-zeebe-gateway", 0, 9600, 5, 15*time.Second)
+func removeSecondaryBrokers(t *testing.T, brokersToRemove []int) {
+	t.Logf("[FAILOVER] Removing secondary brokers %v 🚀", brokersToRemove)
+	require.NotEmpty(t, brokersToRemove, "[FAILOVER] No brokers to remove, run the failover readiness report first")
+
+	gate := zeebeHelpers.RequireSafeForceRemoval(t, &clusters[0].KubectlNamespace, &clusters[failoverRegion].KubectlNamespace, failoverRegion, regionCount, brokersToRemove,
+		zeebeHelpers.RegionByNamespace(clusters.Namespaces()...))
+
+	client, closeFn := zeebeHelpers.NewActuatorTunnel(t, &clusters[0].KubectlNamespace)
 	defer closeFn()
 
 	// Redistribute to remaining brokers
-	res, body := helpers.HttpRequest(
-		t,
-		"PATCH",
-		fmt.Sprintf("http://%s/actuator/cluster?force=true", endpoint),
-		bytes.NewBuffer([]byte(`{"brokers":{"remove":[1,3,5,7]}}`)),
-	)
-	if res == nil {
-		t.Fatal("[FAILOVER] Failed to create request")
-		return
-	}
-
-	require.Equal(t, 202, res.StatusCode)
-	require.NotEmpty(t, body)
-	require.Contains(t, body, "plannedChanges")
-	require.Contains(t, body, "PARTITION_FORCE_RECONFIGURE")
+	response := zeebeHelpers.PlanAndApplyClusterChange(t, client, zeebeHelpers.ClusterChangeRequest{
+		Brokers: &zeebeHelpers.BrokersChange{Remove: brokersToRemove},
+	}, gate)
+	require.NotEmpty(t, response.PlannedChanges)
+	require.True(t, response.HasOperation(zeebeHelpers.OperationPartitionForceReconfigure), "Expected a %s operation", zeebeHelpers.OperationPartitionForceReconfigure)
 
 	t.Log("[FAILOVER] Give the system some time to redistribute the partitions")
 	time.Sleep(5 * time.Second)
 
 	// Check that the removal of obsolete brokers was completed
-	for i := 0; i < 5; i++ {
-		res, body = helpers.HttpRequest(t, "GET", fmt.Sprintf("http://%s/actuator/cluster", endpoint), nil)
-		if res == nil {
-			t.Fatal("[FAILOVER] Failed to create request")
-			return
-		}
-
-		if !strings.Contains(body, "pendingChange") {
-			break
-		}
-		t.Log("[FAILOVER] Broker removal not yet completed, retrying...")
-		time.Sleep(15 * time.Second)
-	}
-
-	require.Equal(t, 200, res.StatusCode)
-	require.NotEmpty(t, body)
-	require.Contains(t, body, "COMPLETED")
-	require.NotContains(t, body, "pendingChange")
-	require.NotContains(t, body, "PARTITION_FORCE_RECONFIGURE")
+	zeebeHelpers.WaitForClusterChange(t, client, response.ChangeId, "broker removal", 5, 15*time.Second)
 }
 
 func disableElasticExportersToSecondary(t *testing.T) {
 	t.Log("[FAILOVER] Disabling Elasticsearch Exporters to secondary 🚀")
-	service := k8s.GetService(t, &primary.KubectlNamespace, "camunda-zeebe-gateway")
-	require.Equal(t, service.Name, "camunda-zeebe-gateway")
 
-	endpoint, closeFn := kubectlHelpers.NewServiceTunnelWithRetry(t, &primary.KubectlNamespace, "camunda-zeebe-gateway", 0, 9600, 5, 15*time.Second)
+	client, closeFn := zeebeHelpers.NewActuatorTunnel(t, &clusters[0].KubectlNamespace)
 	defer closeFn()
 
-	res, body := helpers.HttpRequest(t, "POST", fmt.Sprintf("http://%s/actuator/exporters/camundaregion1/disable", endpoint), nil)
-	if res == nil {
-		t.Fatal("[FAILOVER] Failed to create request")
-		return
-	}
-
-	require.Equal(t, 202, res.StatusCode)
-	require.NotEmpty(t, body)
-	require.Contains(t, body, "DISABLED")
-	require.Contains(t, body, "PARTITION_DISABLE_EXPORTER")
+	exporters := zeebeHelpers.NewExporterManager(client)
+	exporters.Disable(t, zeebeHelpers.CamundaExporterId(failoverRegion))
 
-	// Check that the exporter was disabled
-	for i := 0; i < 5; i++ {
-		res, body = helpers.HttpRequest(t, "GET", fmt.Sprintf("http://%s/actuator/exporters", endpoint), nil)
-		if res == nil {
-			t.Fatal("[FAILOVER] Failed to create request")
-			return
-		}
-
-		if strings.Contains(body, "{\"exporterId\":\"camundaregion1\",\"status\":\"DISABLED\"}") {
-			break
-		}
-		t.Log("[FAILOVER] Exporter not yet disabled, retrying...")
-		time.Sleep(15 * time.Second)
-	}
-
-	require.Equal(t, 200, res.StatusCode)
-	require.NotEmpty(t, body)
-	require.Contains(t, body, "{\"exporterId\":\"camundaregion0\",\"status\":\"ENABLED\"}")
-	require.Contains(t, body, "{\"exporterId\":\"camundaregion1\",\"status\":\"DISABLED\"}")
+	expected := camundaExporterStatus(zeebeHelpers.ExporterStatusEnabled)
+	expected[zeebeHelpers.CamundaExporterId(failoverRegion)] = zeebeHelpers.ExporterStatusDisabled
+	exporters.RequireStatus(t, expected)
 }
 
 func enableElasticExportersToSecondary(t *testing.T) {
 	t.Log("[FAILBACK] Enabling Elasticsearch Exporters to secondary 🚀")
-	service := k8s.GetService(t, &primary.KubectlNamespace, "camunda-zeebe-gateway")
-	require.Equal(t, service.Name, "camunda-zeebe-gateway")
 
-	endpoint, closeFn := kubectlHelpers.NewServiceTunnelWithRetry(t, &primary.KubectlNamespace, "camunda-zeebe-gateway", 0, 9600, 5, 15*time.Second)
+	client, closeFn := zeebeHelpers.NewActuatorTunnel(t, &clusters[0].KubectlNamespace)
 	defer closeFn()
 
-	res, body := helpers.HttpRequest(t, "POST", fmt.Sprintf("http://%s/actuator/exporters/camundaregion1/enable", endpoint), bytes.NewBuffer([]byte(`{"initializeFrom":"camundaregion0"}`)))
-	if res == nil {
-		t.Fatal("[FAILBACK] Failed to create request")
-		return
-	}
-
-	require.Equal(t, 202, res.StatusCode)
-	require.NotEmpty(t, body)
-	require.Contains(t, body, "ENABLED")
-	require.Contains(t, body, "PARTITION_ENABLE_EXPORTER")
-
-	// Check that the exporter was enabled
 	// It can take a while until the exporter is fully enabled again
-	for i := 0; i < 30; i++ {
-		res, body = helpers.HttpRequest(t, "GET", fmt.Sprintf("http://%s/actuator/exporters", endpoint), nil)
-		if res == nil {
-			t.Fatal("[FAILBACK] Failed to create request")
-			return
-		}
+	exporters := zeebeHelpers.NewExporterManager(client)
+	exporters.Enable(t, zeebeHelpers.CamundaExporterId(failoverRegion), zeebeHelpers.CamundaExporterId(0))
 
-		if strings.Contains(body, "{\"exporterId\":\"camundaregion1\",\"status\":\"ENABLED\"}") {
-			break
-		}
-		t.Log("[FAILBACK] Exporter not yet enabled, retrying...")
-		time.Sleep(15 * time.Second)
-	}
+	exporters.RequireStatus(t, camundaExporterStatus(zeebeHelpers.ExporterStatusEnabled))
+}
 
-	require.Equal(t, 200, res.StatusCode)
-	require.NotEmpty(t, body)
-	require.Contains(t, body, "{\"exporterId\":\"camundaregion0\",\"status\":\"ENABLED\"}")
-	require.Contains(t, body, "{\"exporterId\":\"camundaregion1\",\"status\":\"ENABLED\"}")
+// camundaExporterStatus expects the Camunda exporter of every region in the given status
+func camundaExporterStatus(status string) map[string]string {
+	expected := map[string]string{}
+	for region := range clusters {
+		expected[zeebeHelpers.CamundaExporterId(region)] = status
+	}
+	return expected
 }
 
 func addSecondaryBrokers(t *testing.T) {
 	t.Log("[FAILBACK] Adding secondary brokers 🚀")
-	service := k8s.GetService(t, &primary.KubectlNamespace, "camunda-zeebe-gateway")
-	require.Equal(t, service.Name, "camunda-zeebe-gateway")
 
-	endpoint, closeFn := kubectlHelpers.NewServiceTunnelWithRetry(t, &primary.KubectlNamespace, "camunda-zeebe-gateway", 0, 9600, 5, 15*time.Second)
+	client, closeFn := zeebeHelpers.NewActuatorTunnel(t, &clusters[0].KubectlNamespace)
 	defer closeFn()
 
 	// Redistribute to new brokers
-	res, body := helpers.HttpRequest(
-		t,
-		"PATCH",
-		fmt.Sprintf("http://%s/actuator/cluster", endpoint),
-		bytes.NewBuffer([]byte(`{"brokers":{"add":[1,3,5,7]},"partitions":{"replicationFactor":4}}`)),
-	)
-	if res == nil {
-		t.Fatal("[FAILBACK] Failed to create request")
-		return
+	brokers := zeebeHelpers.RegionNodeIds(failoverRegion, regionCount, clusterSize)
+	change := zeebeHelpers.ClusterChangeRequest{
+		Brokers:    &zeebeHelpers.BrokersChange{Add: brokers},
+		Partitions: &zeebeHelpers.PartitionsChange{ReplicationFactor: replicationFactor},
+	}
+	response := zeebeHelpers.PlanAndApplyClusterChange(t, client, change, nil)
+	require.NotEmpty(t, response.PlannedChanges)
+	for _, id := range brokers {
+		require.NotNil(t, response.ExpectedBroker(id), "Expected broker %d to be part of the expected topology", id)
 	}
-
-	require.Equal(t, 202, res.StatusCode)
-	require.NotEmpty(t, body)
-	require.Contains(t, body, "\"id\":8,\"state\":\"ACTIVE\"")
 
 	// Check that the addition of new brokers was completed
-	// This can take a while
-	for i := 0; i < 20; i++ {
-		res, body = helpers.HttpRequest(t, "GET", fmt.Sprintf("http://%s/actuator/cluster", endpoint), nil)
-		if res == nil {
-			t.Fatal("[FAILBACK] Failed to create request")
-			return
+	// This can take a while, a stuck addition is cancelled and the half added brokers are removed again
+	zeebeHelpers.WaitForClusterChangeOrRollback(t, client, response.ChangeId, change, "broker addition", 20, 15*time.Second)
+
+	// Check that the new brokers have become ready, now that they're integrated in the zeebe cluster again
+	k8s.RunKubectl(t, &clusters[failoverRegion].KubectlNamespace, "rollout", "status", "--watch", "--timeout=300s", "statefulset/camunda-zeebe")
+
+	k8s.WaitUntilDeploymentAvailable(t, &clusters[0].KubectlNamespace, "camunda-connectors", retries, 15*time.Second)
+}
+
+// Failback preconditions and completion checks
+
+func primaryGatewayReachable(t *testing.T) error {
+	_, err := kubectlHelpers.GetClusterTopologyE(t, &clusters[0].KubectlNamespace)
+	return err
+}
+
+func secondaryBrokersDeployed(t *testing.T) error {
+	replicas, err := kubectlHelpers.GetStatefulSetReplicasE(t, &clusters[failoverRegion].KubectlNamespace, "camunda-zeebe")
+	if err != nil {
+		return fmt.Errorf("secondary brokers are not deployed: %w", err)
+	}
+	if replicas == 0 {
+		return fmt.Errorf("secondary brokers are scaled to zero")
+	}
+	return nil
+}
+
+// exportingIn reports whether every partition leader is in the given exporter phase
+func exportingIn(phase string) func(t *testing.T) bool {
+	return func(t *testing.T) bool {
+		phases := zeebeHelpers.NewExportingControl(&clusters[0].KubectlNamespace, clusters.KubectlNamespaces()...).LeaderPhases(t)
+		for _, current := range phases {
+			if current != phase {
+				return false
+			}
 		}
+		return len(phases) > 0
+	}
+}
 
-		if !strings.Contains(body, "pendingChange") {
-			break
+func requireExportingIn(phase string) func(t *testing.T) error {
+	return func(t *testing.T) error {
+		if !exportingIn(phase)(t) {
+			return fmt.Errorf("exporting is not %s on every partition", phase)
 		}
-		t.Log("[FAILBACK] Broker addition not yet completed, retrying...")
-		time.Sleep(15 * time.Second)
+		return nil
 	}
+}
 
-	require.Equal(t, 200, res.StatusCode)
-	require.NotEmpty(t, body)
-	require.Contains(t, body, "COMPLETED")
-	require.NotContains(t, body, "pendingChange")
+// exporterIn reports whether the exporter has the given status
+func exporterIn(exporterId, status string) func(t *testing.T) bool {
+	return func(t *testing.T) bool {
+		client, closeFn := zeebeHelpers.NewActuatorTunnel(t, &clusters[0].KubectlNamespace)
+		defer closeFn()
 
-	// Check that the new brokers have become ready, now that they're integrated in the zeebe cluster again
-	k8s.RunKubectl(t, &secondary.KubectlNamespace, "rollout", "status", "--watch", "--timeout=300s", "statefulset/camunda-zeebe")
+		exporters, err := client.GetExporters()
+		require.NoError(t, err, "Failed to list exporters")
+		return zeebeHelpers.ExporterStatusOf(exporters, exporterId) == status
+	}
+}
 
-	k8s.WaitUntilDeploymentAvailable(t, &primary.KubectlNamespace, "camunda-connectors", retries, 15*time.Second)
+func requireExporterIn(exporterId, status string) func(t *testing.T) error {
+	return func(t *testing.T) error {
+		if !exporterIn(exporterId, status)(t) {
+			return fmt.Errorf("exporter %s is not %s", exporterId, status)
+		}
+		return nil
+	}
+}
+
+// secondaryBrokersJoined reports whether all secondary brokers are active members of the cluster
+func secondaryBrokersJoined(t *testing.T) bool {
+	client, closeFn := zeebeHelpers.NewActuatorTunnel(t, &clusters[0].KubectlNamespace)
+	defer closeFn()
+
+	topology, err := client.GetCluster()
+	require.NoError(t, err, "Failed to query cluster topology")
+	if topology.HasPendingChange() {
+		return false
+	}
+	for _, id := range zeebeHelpers.RegionNodeIds(failoverRegion, regionCount, clusterSize) {
+		if broker := topology.Broker(id); broker == nil || broker.State != zeebeHelpers.BrokerStateActive {
+			return false
+		}
+	}
+	return true
+}
+
+func requireSecondaryBrokersJoined(t *testing.T) error {
+	if !secondaryBrokersJoined(t) {
+		return fmt.Errorf("secondary brokers are not part of the cluster")
+	}
+	return nil
 }
 
 func checkMigrationSucceed(t *testing.T) {
 	t.Log("[MIGRATION CHECK] Checking if Camunda Platform Migration is running 🚦")
 
-	k8s.RunKubectl(t, &primary.KubectlNamespace, "get", "pods")
-	k8s.RunKubectl(t, &secondary.KubectlNamespace, "get", "pods")
+	for region := range clusters {
+		k8s.RunKubectl(t, &clusters[region].KubectlNamespace, "get", "pods")
+	}
 
 	// Waiting for the importer to be ready
-	k8s.WaitUntilDeploymentAvailable(t, &primary.KubectlNamespace, "camunda-zeebe-migration-importer", retries, 15*time.Second)
-	k8s.WaitUntilDeploymentAvailable(t, &secondary.KubectlNamespace, "camunda-zeebe-migration-importer", retries, 15*time.Second)
+	for region := range clusters {
+		k8s.WaitUntilDeploymentAvailable(t, &clusters[region].KubectlNamespace, "camunda-zeebe-migration-importer", retries, 15*time.Second)
+	}
 
 	// If the Job succeeds, then the migration was successfully completed
-	k8s.WaitUntilJobSucceed(t, &primary.KubectlNamespace, "camunda-zeebe-migration-data", retries, 30*time.Second)
-	k8s.WaitUntilJobSucceed(t, &secondary.KubectlNamespace, "camunda-zeebe-migration-data", retries, 30*time.Second)
+	for region := range clusters {
+		k8s.WaitUntilJobSucceed(t, &clusters[region].KubectlNamespace, "camunda-zeebe-migration-data", retries, 30*time.Second)
+	}
 }
 
 func postMigrationCleanup(t *testing.T) {
 	t.Log("[MIGRATION CLEANUP] Disabling old exporters after Camunda Platform Migration 🚦")
 
-	service := k8s.GetService(t, &primary.KubectlNamespace, "camunda-zeebe-gateway")
-	require.Equal(t, service.Name, "camunda-zeebe-gateway")
-
-	endpoint, closeFn := kubectlHelpers.NewServiceTunnelWithRetry(t, &primary.KubectlNamespace, "camunda-zeebe-gateway", 0, 9600, 5, 15*time.Second)
+	client, closeFn := zeebeHelpers.NewActuatorTunnel(t, &clusters[0].KubectlNamespace)
 	defer closeFn()
 
-	// Disable old migration exporters
-	exporterIDs := []string{"elasticsearchregion0", "elasticsearchregion1"}
-	for _, id := range exporterIDs {
-		t.Logf("[MIGRATION CLEANUP] Disabling exporter %s", id)
-		res, body := helpers.HttpRequest(
-			t,
-			"POST",
-			fmt.Sprintf("http://%s/actuator/exporters/%s/disable", endpoint, id),
-			nil,
-		)
-		if res == nil {
-			t.Fatalf("[MIGRATION CLEANUP] Failed to create request for exporter %s", id)
-			return
-		}
-		require.Equal(t, 202, res.StatusCode, "unexpected status disabling exporter %s", id)
-		require.NotEmpty(t, body)
-		require.Contains(t, body, "DISABLED")
-
-		// Wait until the cluster reports the last change as COMPLETED before continuing
-		for i := 0; i < 20; i++ {
-			resCluster, bodyCluster := helpers.HttpRequest(
-				t,
-				"GET",
-				fmt.Sprintf("http://%s/actuator/cluster", endpoint),
-				nil,
-			)
-			if resCluster == nil {
-				t.Fatal("[MIGRATION CLEANUP] Failed to query cluster status")
-				return
-			}
-			if strings.Contains(bodyCluster, "\"lastChange\"") &&
-				strings.Contains(bodyCluster, "\"status\":\"COMPLETED\"") &&
-				!strings.Contains(bodyCluster, "pendingChange") {
-				t.Log("[MIGRATION CLEANUP] Cluster lastChange status is COMPLETED")
-				break
-			}
-			if i == 19 {
-				t.Fatalf("[MIGRATION CLEANUP] Cluster lastChange did not reach COMPLETED. Body: %s", bodyCluster)
-			}
-			t.Log("[MIGRATION CLEANUP] Cluster change not yet COMPLETED, retrying...")
-			time.Sleep(10 * time.Second)
-		}
-	}
-
-	// Confirm both exporters are disabled
-	var (
-		res  *http.Response
-		body string
-	)
-	for i := 0; i < 10; i++ {
-		res, body = helpers.HttpRequest(
-			t,
-			"GET",
-			fmt.Sprintf("http://%s/actuator/exporters", endpoint),
-			nil,
-		)
-		if res == nil {
-			t.Fatal("[MIGRATION CLEANUP] Failed to query exporters status")
-			return
-		}
+	exporters := zeebeHelpers.NewExporterManager(client)
+	exporters.MaxRetries = 20
+	exporters.Interval = 10 * time.Second
 
-		if strings.Contains(body, "\"exporterId\":\"elasticsearchregion0\",\"status\":\"DISABLED\"") &&
-			strings.Contains(body, "\"exporterId\":\"elasticsearchregion1\",\"status\":\"DISABLED\"") {
-			break
-		}
-		t.Log("[MIGRATION CLEANUP] Exporters not yet disabled, retrying...")
-		time.Sleep(10 * time.Second)
+	// Disable old migration exporters
+	expected := map[string]string{}
+	for region := range clusters {
+		id := zeebeHelpers.ElasticsearchExporterId(region)
+		exporters.Disable(t, id)
+		expected[id] = zeebeHelpers.ExporterStatusDisabled
 	}
 
-	require.Equal(t, 200, res.StatusCode)
-	require.NotEmpty(t, body)
-	require.Contains(t, body, "\"exporterId\":\"elasticsearchregion0\",\"status\":\"DISABLED\"")
-	require.Contains(t, body, "\"exporterId\":\"elasticsearchregion1\",\"status\":\"DISABLED\"")
+	// Confirm all migration exporters are disabled
+	exporters.RequireStatus(t, expected)
 	t.Log("[MIGRATION CLEANUP] Successfully disabled migration exporters")
 }
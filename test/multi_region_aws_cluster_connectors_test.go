@@ -12,6 +12,7 @@ import (
 
 	"multiregiontests/internal/helpers"
 	kubectlHelpers "multiregiontests/internal/helpers/kubectl"
+	versionHelpers "multiregiontests/internal/helpers/versions"
 
 	"github.com/gruntwork-io/terratest/modules/k8s"
 	"github.com/stretchr/testify/require"
@@ -31,6 +32,7 @@ const (
 // 4. Verifies the mock server received exactly 10 requests
 func TestConnectorWebhookFlow(t *testing.T) {
 	t.Log("[CONNECTOR TEST] Testing Connector Webhook Flow in multi-region mode 🚀")
+	requireCapabilities(t, versionHelpers.OrchestrationValues)
 
 	if globalImageTag != "" {
 		t.Log("[GLOBAL IMAGE TAG] Overwriting image tag for all Camunda images with " + globalImageTag)
@@ -38,10 +40,7 @@ func TestConnectorWebhookFlow(t *testing.T) {
 	}
 
 	// Runs the tests sequentially
-	for _, testFuncs := range []struct {
-		name  string
-		tfunc func(*testing.T)
-	}{
+	runSteps(t, []testStep{
 		{"TestInitKubernetesHelpers", initKubernetesHelpers},
 		{"TestDeployMockApiServer", deployMockApiServer},
 		{"TestDeployConnectorBpmnProcess", deployConnectorBpmnProcess},
@@ -50,21 +49,19 @@ func TestConnectorWebhookFlow(t *testing.T) {
 		{"TestVerifyMockServerReceivedRequests", verifyMockServerReceivedRequests},
 		{"TestVerifyConnectorsProcessedJobs", verifyConnectorsProcessedJobs},
 		{"TestCleanupMockApiServer", cleanupMockApiServer},
-	} {
-		t.Run(testFuncs.name, testFuncs.tfunc)
-	}
+	})
 }
 
 // deployMockApiServer deploys the mock API server
 func deployMockApiServer(t *testing.T) {
-	t.Log("[MOCK SERVER] Deploying mock API server to primary region 🚀")
+	t.Log("[MOCK SERVER] Deploying mock API server to region 0 🚀")
 
-	k8s.KubectlApply(t, &primary.KubectlNamespace, mockServerManifest)
+	k8s.KubectlApply(t, &clusters[0].KubectlNamespace, mockServerManifest)
 
 	// Apply Teleport tolerations and affinity patch if running on Teleport
 	if helpers.IsTeleportEnabled() {
 		t.Log("[MOCK SERVER] Applying Teleport tolerations and affinity patch")
-		k8s.RunKubectl(t, &primary.KubectlNamespace, "patch", "statefulset", "mock-api-server",
+		k8s.RunKubectl(t, &clusters[0].KubectlNamespace, "patch", "statefulset", "mock-api-server",
 			"--patch-file", mockServerTeleportManifest, "--type=strategic")
 
 		time.Sleep(15 * time.Second) // Give some time for the patch to take effect as it has to reschedule the pod
@@ -75,12 +72,12 @@ func deployMockApiServer(t *testing.T) {
 func waitForMockApiServerReady(t *testing.T) {
 	t.Log("[MOCK SERVER] Waiting for mock API server to be ready 🕐")
 
-	// Wait for the StatefulSet pod to be available in primary region
-	k8s.WaitUntilPodAvailable(t, &primary.KubectlNamespace, "mock-api-server-0", 20, 10*time.Second)
-	t.Log("[MOCK SERVER] Mock API server ready in primary region")
+	// Wait for the StatefulSet pod to be available in region 0
+	k8s.WaitUntilPodAvailable(t, &clusters[0].KubectlNamespace, "mock-api-server-0", 20, 10*time.Second)
+	t.Log("[MOCK SERVER] Mock API server ready in region 0")
 
 	// Verify the service is accessible by checking the health endpoint
-	endpoint, closeFn := kubectlHelpers.NewServiceTunnelWithRetry(t, &primary.KubectlNamespace, "mock-api-server", 0, 8080, 5, 10*time.Second)
+	endpoint, closeFn := kubectlHelpers.NewServiceTunnelWithRetry(t, &clusters[0].KubectlNamespace, "mock-api-server", 0, 8080, 5, 10*time.Second)
 	defer closeFn()
 
 	var res *http.Response
@@ -106,7 +103,7 @@ func waitForMockApiServerReady(t *testing.T) {
 func deployConnectorBpmnProcess(t *testing.T) {
 	t.Log("[CONNECTOR PROCESS] Deploying connector BPMN process to Zeebe 🚀")
 
-	kubectlHelpers.DeployBpmnProcess(t, &primary.KubectlNamespace, connectorBpmnPath, "<default>", "c8-multi-region-dummy-connector-flow")
+	kubectlHelpers.DeployBpmnProcess(t, &clusters[0].KubectlNamespace, connectorBpmnPath, "<default>", "c8-multi-region-dummy-connector-flow")
 
 	// Wait for process to be propagated
 	t.Log("[CONNECTOR PROCESS] Waiting for process to be propagated...")
@@ -117,8 +114,8 @@ func deployConnectorBpmnProcess(t *testing.T) {
 func triggerWebhookWorkflow(t *testing.T) {
 	t.Logf("[WEBHOOK TRIGGER] Triggering webhook workflow %d times 🚀", webhookTriggerCount)
 
-	// Get the mock server endpoint from primary region to use as target URL
-	mockEndpoint, mockCloseFn := kubectlHelpers.NewServiceTunnelWithRetry(t, &primary.KubectlNamespace, "mock-api-server", 0, 8080, 5, 10*time.Second)
+	// Get the mock server endpoint from region 0 to use as target URL
+	mockEndpoint, mockCloseFn := kubectlHelpers.NewServiceTunnelWithRetry(t, &clusters[0].KubectlNamespace, "mock-api-server", 0, 8080, 5, 10*time.Second)
 	defer mockCloseFn()
 
 	// Clear any existing requests on the mock server first
@@ -127,13 +124,13 @@ func triggerWebhookWorkflow(t *testing.T) {
 	t.Log("[WEBHOOK TRIGGER] Cleared mock server request history")
 
 	// Get the connectors webhook endpoint
-	connectorsEndpoint, connectorsCloseFn := kubectlHelpers.NewServiceTunnelWithRetry(t, &primary.KubectlNamespace, "camunda-connectors", 0, 8080, 5, 10*time.Second)
+	connectorsEndpoint, connectorsCloseFn := kubectlHelpers.NewServiceTunnelWithRetry(t, &clusters[0].KubectlNamespace, "camunda-connectors", 0, 8080, 5, 10*time.Second)
 	defer connectorsCloseFn()
 
 	// The mock server URL that the connector will call (using Kubernetes internal DNS)
 	// The connector runs inside the cluster, so it needs to use the StatefulSet pod DNS name
 	// Format: <pod-name>.<headless-service>.<namespace>.svc.cluster.local
-	mockServerInternalUrl := fmt.Sprintf("http://mock-api-server-0.mock-api-server-peer.%s.svc.cluster.local:8080/webhook-callback", primary.KubectlNamespace.Namespace)
+	mockServerInternalUrl := fmt.Sprintf("http://mock-api-server-0.mock-api-server-peer.%s.svc.cluster.local:8080/webhook-callback", clusters[0].KubectlNamespace.Namespace)
 
 	for i := 1; i <= webhookTriggerCount; i++ {
 		t.Logf("[WEBHOOK TRIGGER] Triggering workflow %d/%d", i, webhookTriggerCount)
@@ -193,7 +190,7 @@ type MockServerRequest struct {
 func verifyMockServerReceivedRequests(t *testing.T) {
 	t.Logf("[VERIFICATION] Verifying mock server received exactly %d requests 🔍", webhookTriggerCount)
 
-	endpoint, closeFn := kubectlHelpers.NewServiceTunnelWithRetry(t, &primary.KubectlNamespace, "mock-api-server", 0, 8080, 5, 10*time.Second)
+	endpoint, closeFn := kubectlHelpers.NewServiceTunnelWithRetry(t, &clusters[0].KubectlNamespace, "mock-api-server", 0, 8080, 5, 10*time.Second)
 	defer closeFn()
 
 	var requestsResponse MockServerRequestsResponse
@@ -245,40 +242,38 @@ func verifyMockServerReceivedRequests(t *testing.T) {
 	}
 }
 
-// verifyConnectorsProcessedJobs checks that both connector deployments have processed jobs
+// verifyConnectorsProcessedJobs checks that the connector deployments of all regions have processed jobs
 func verifyConnectorsProcessedJobs(t *testing.T) {
-	t.Log("[CONNECTORS CHECK] Verifying both connector deployments processed jobs 🔍")
-
-	// Check primary region connectors
-	primaryLogs, err := k8s.RunKubectlAndGetOutputE(t, &primary.KubectlNamespace, "logs", "deployment/camunda-connectors", "--tail=1000")
-	require.NoError(t, err, "Failed to get primary region connector logs")
-	primaryJobCount := strings.Count(primaryLogs, "Completing job")
-	t.Logf("[CONNECTORS CHECK] Primary region connectors completed %d jobs", primaryJobCount)
-
-	// Check secondary region connectors
-	secondaryLogs, err := k8s.RunKubectlAndGetOutputE(t, &secondary.KubectlNamespace, "logs", "deployment/camunda-connectors", "--tail=1000")
-	require.NoError(t, err, "Failed to get secondary region connector logs")
-	secondaryJobCount := strings.Count(secondaryLogs, "Completing job")
-	t.Logf("[CONNECTORS CHECK] Secondary region connectors completed %d jobs", secondaryJobCount)
+	t.Log("[CONNECTORS CHECK] Verifying all connector deployments processed jobs 🔍")
+
+	totalJobs := 0
+	jobCounts := make([]int, len(clusters))
+	for region := range clusters {
+		logs, err := k8s.RunKubectlAndGetOutputE(t, &clusters[region].KubectlNamespace, "logs", "deployment/camunda-connectors", "--tail=1000")
+		require.NoError(t, err, "Failed to get region %d connector logs", region)
+		jobCounts[region] = strings.Count(logs, "Completing job")
+		t.Logf("[CONNECTORS CHECK] Region %d connectors completed %d jobs", region, jobCounts[region])
+		totalJobs += jobCounts[region]
+	}
 
-	// Both regions should have processed jobs
-	require.Greater(t, primaryJobCount, 0, "Primary region connectors did not process any jobs (no 'Completing job' in logs)")
-	require.Greater(t, secondaryJobCount, 0, "Secondary region connectors did not process any jobs (no 'Completing job' in logs)")
+	// Every region should have processed jobs
+	for region, jobCount := range jobCounts {
+		require.Greater(t, jobCount, 0, "Region %d connectors did not process any jobs (no 'Completing job' in logs)", region)
+	}
 
 	// Log total jobs processed
-	totalJobs := primaryJobCount + secondaryJobCount
-	t.Logf("[CONNECTORS CHECK] Total jobs completed: %d (primary: %d, secondary: %d)", totalJobs, primaryJobCount, secondaryJobCount)
+	t.Logf("[CONNECTORS CHECK] Total jobs completed: %d (per region: %v)", totalJobs, jobCounts)
 
 	// Verify total matches expected (each webhook triggers one REST connector job)
 	require.GreaterOrEqual(t, totalJobs, webhookTriggerCount,
 		"Expected at least %d jobs to be completed, but only %d were found", webhookTriggerCount, totalJobs)
 
-	t.Log("[CONNECTORS CHECK] ✅ Both connector deployments have processed jobs")
+	t.Log("[CONNECTORS CHECK] ✅ All connector deployments have processed jobs")
 }
 
 // cleanupMockApiServer removes the mock API server
 func cleanupMockApiServer(t *testing.T) {
 	t.Log("[CLEANUP] Removing mock API server 🧹")
 
-	k8s.KubectlDelete(t, &primary.KubectlNamespace, mockServerManifest)
+	k8s.KubectlDelete(t, &clusters[0].KubectlNamespace, mockServerManifest)
 }
@@ -1,103 +1,150 @@
 package test
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"strings"
 	"testing"
 	"time"
 
 	"multiregiontests/internal/helpers"
 	kubectlHelpers "multiregiontests/internal/helpers/kubectl"
+	versionHelpers "multiregiontests/internal/helpers/versions"
+	zeebeHelpers "multiregiontests/internal/helpers/zeebe"
 
 	"github.com/gruntwork-io/terratest/modules/k8s"
 	"github.com/stretchr/testify/require"
 )
 
 // TestZeebeClusterScaleUpBrokers tests scaling Zeebe brokers in a multi-region setup
-// Initial state: 8 brokers (4 per region), 8 partitions
-// Target state: 12 brokers (6 per region), 8 partitions
+// Adds one broker per region to the topology observed at the start, e.g. 8 brokers (4 per region) to 10 brokers (5 per region)
 // Reference: https://docs.camunda.io/docs/self-managed/components/orchestration-cluster/zeebe/operations/cluster-scaling/
 func TestZeebeClusterScaleUpBrokers(t *testing.T) {
 	t.Log("[CLUSTER SCALING TEST] Testing Zeebe broker scaling in multi-region mode 🚀")
+	requireCapabilities(t, versionHelpers.OrchestrationValues, versionHelpers.ActuatorCluster)
 
 	if globalImageTag != "" {
 		t.Log("[GLOBAL IMAGE TAG] Overwriting image tag for all Camunda images with " + globalImageTag)
 		baseHelmVars = helpers.OverwriteImageTag(baseHelmVars, globalImageTag)
 	}
 
+	var start kubectlHelpers.ClusterInfo
+	var change scalingChange
+	var plan zeebeHelpers.BrokerScalingPlan
+	var snapshots *zeebeHelpers.TopologyRecorder
+
 	// Runs the tests sequentially
-	for _, testFuncs := range []struct {
-		name  string
-		tfunc func(*testing.T)
-	}{
+	runSteps(t, []testStep{
 		{"TestInitKubernetesHelpers", initKubernetesHelpers},
-		{"TestVerifyClusterTopology", func(t *testing.T) { verifyClusterTopology(t, 8, 8) }},
-		{"TestScaleUpBrokerStatefulSets", func(t *testing.T) { scaleUpBrokerStatefulSets(t, 5) }},
-		{"TestWaitForNewBrokersToStart", func(t *testing.T) { waitForNewBrokersToStart(t, 4, 1) }},
-		{"TestAddNewBrokersToCluster", func(t *testing.T) { addNewBrokersToCluster(t, []int{8, 9}) }},
-		{"TestWaitForBrokerScalingComplete", func(t *testing.T) { waitForScalingComplete(t, "broker scaling", 30) }},
-		{"TestVerifyScaledBrokerTopology", func(t *testing.T) { verifyClusterTopology(t, 10, 8) }},
-	} {
-		t.Run(testFuncs.name, testFuncs.tfunc)
-	}
+		{"TestInitTopologyRecorder", func(*testing.T) { snapshots = newTopologyRecorder(t, "broker-scale-up") }},
+		{"TestObserveClusterTopology", func(t *testing.T) { start = observeClusterTopology(t) }},
+		{"TestSnapshotTopologyBeforeScaling", func(t *testing.T) { snapshots.Snapshot(t, "before scaling") }},
+		{"TestPlanBrokerScaling", func(t *testing.T) { plan = planBrokerScaling(t, brokersPerRegion(start)+1) }},
+		{"TestScaleUpBrokerStatefulSets", func(t *testing.T) { scaleUpBrokerStatefulSets(t, plan) }},
+		{"TestWaitForNewBrokersToStart", func(t *testing.T) { waitForNewBrokersToStart(t, plan) }},
+		{"TestAddNewBrokersToCluster", func(t *testing.T) { change = addNewBrokersToCluster(t, plan) }},
+		{"TestWaitForBrokerScalingComplete", func(t *testing.T) { waitForScalingComplete(t, "broker scaling", change, 30) }},
+		{"TestSnapshotTopologyAfterScaling", func(t *testing.T) { snapshots.Snapshot(t, "after scaling") }},
+		{"TestVerifyScaledBrokerTopology", func(t *testing.T) { verifyClusterTopology(t, plan.ClusterSize(), start.PartitionsCount) }},
+	})
 }
 
 // TestZeebeClusterScaleUpPartitions tests scaling partitions in a multi-region setup
-// Initial state: 12 brokers (6 per region), 8 partitions
-// Target state: 12 brokers (6 per region), 12 partitions
+// Adds two partitions to the topology observed at the start, e.g. 8 partitions to 10 partitions
 // Reference: https://docs.camunda.io/docs/self-managed/components/orchestration-cluster/zeebe/operations/cluster-scaling/
 func TestZeebeClusterScaleUpPartitions(t *testing.T) {
 	t.Log("[CLUSTER SCALING TEST] Testing Zeebe partition scaling in multi-region mode 🚀")
+	requireCapabilities(t, versionHelpers.OrchestrationValues, versionHelpers.ActuatorCluster)
 
 	if globalImageTag != "" {
 		t.Log("[GLOBAL IMAGE TAG] Overwriting image tag for all Camunda images with " + globalImageTag)
 		baseHelmVars = helpers.OverwriteImageTag(baseHelmVars, globalImageTag)
 	}
 
+	var start kubectlHelpers.ClusterInfo
+	var change scalingChange
+	var snapshots *zeebeHelpers.TopologyRecorder
+
 	// Runs the tests sequentially
-	for _, testFuncs := range []struct {
-		name  string
-		tfunc func(*testing.T)
-	}{
+	runSteps(t, []testStep{
 		{"TestInitKubernetesHelpers", initKubernetesHelpers},
-		{"TestVerifyClusterTopology", func(t *testing.T) { verifyClusterTopology(t, 10, 8) }},
-		{"TestScaleUpPartitions", func(t *testing.T) { scaleUpPartitions(t, 10, 4) }},
-		{"TestWaitForPartitionScalingComplete", func(t *testing.T) { waitForScalingComplete(t, "partition scaling", 60) }},
-		{"TestVerifyScaledPartitionTopology", func(t *testing.T) { verifyClusterTopology(t, 10, 10) }},
-	} {
-		t.Run(testFuncs.name, testFuncs.tfunc)
-	}
+		{"TestInitTopologyRecorder", func(*testing.T) { snapshots = newTopologyRecorder(t, "partition-scale-up") }},
+		{"TestObserveClusterTopology", func(t *testing.T) { start = observeClusterTopology(t) }},
+		{"TestSnapshotTopologyBeforeScaling", func(t *testing.T) { snapshots.Snapshot(t, "before scaling") }},
+		{"TestScaleUpPartitions", func(t *testing.T) { change = scaleUpPartitions(t, start.PartitionsCount+2, start.ReplicationFactor) }},
+		{"TestWaitForPartitionScalingComplete", func(t *testing.T) { waitForScalingComplete(t, "partition scaling", change, 60) }},
+		{"TestSnapshotTopologyAfterScaling", func(t *testing.T) { snapshots.Snapshot(t, "after scaling") }},
+		{"TestVerifyScaledPartitionTopology", func(t *testing.T) { verifyClusterTopology(t, start.ClusterSize, start.PartitionsCount+2) }},
+	})
 }
 
 // TestZeebeClusterScaleUpBrokersAndPartitions tests scaling both brokers and partitions simultaneously
-// Initial state: 12 brokers (6 per region), 12 partitions
-// Target state: 16 brokers (8 per region), 16 partitions
+// Adds one broker per region and two partitions to the topology observed at the start, e.g. 10 brokers and 10 partitions to 12 of each
 // Reference: https://docs.camunda.io/docs/self-managed/components/orchestration-cluster/zeebe/operations/cluster-scaling/
 func TestZeebeClusterScaleUpBothBrokersAndPartitions(t *testing.T) {
 	t.Log("[CLUSTER SCALING TEST] Testing Zeebe broker and partition scaling in multi-region mode 🚀")
+	requireCapabilities(t, versionHelpers.OrchestrationValues, versionHelpers.ActuatorCluster)
 
 	if globalImageTag != "" {
 		t.Log("[GLOBAL IMAGE TAG] Overwriting image tag for all Camunda images with " + globalImageTag)
 		baseHelmVars = helpers.OverwriteImageTag(baseHelmVars, globalImageTag)
 	}
 
+	var start kubectlHelpers.ClusterInfo
+	var change scalingChange
+	var plan zeebeHelpers.BrokerScalingPlan
+	var snapshots *zeebeHelpers.TopologyRecorder
+
 	// Runs the tests sequentially
-	for _, testFuncs := range []struct {
-		name  string
-		tfunc func(*testing.T)
-	}{
+	runSteps(t, []testStep{
 		{"TestInitKubernetesHelpers", initKubernetesHelpers},
-		{"TestVerifyClusterTopology", func(t *testing.T) { verifyClusterTopology(t, 10, 10) }},
-		{"TestScaleUpBrokerStatefulSets", func(t *testing.T) { scaleUpBrokerStatefulSets(t, 6) }},
-		{"TestWaitForNewBrokersToStart", func(t *testing.T) { waitForNewBrokersToStart(t, 5, 1) }},
-		{"TestScaleUpBrokersAndPartitions", func(t *testing.T) { scaleUpBrokersAndPartitions(t, []int{10, 11}, 12, 4) }},
-		{"TestWaitForCombinedScalingComplete", func(t *testing.T) { waitForScalingComplete(t, "combined broker and partition scaling", 60) }},
-		{"TestVerifyScaledClusterTopology", func(t *testing.T) { verifyClusterTopology(t, 12, 12) }},
-	} {
-		t.Run(testFuncs.name, testFuncs.tfunc)
+		{"TestInitTopologyRecorder", func(*testing.T) { snapshots = newTopologyRecorder(t, "broker-and-partition-scale-up") }},
+		{"TestObserveClusterTopology", func(t *testing.T) { start = observeClusterTopology(t) }},
+		{"TestSnapshotTopologyBeforeScaling", func(t *testing.T) { snapshots.Snapshot(t, "before scaling") }},
+		{"TestPlanBrokerScaling", func(t *testing.T) { plan = planBrokerScaling(t, brokersPerRegion(start)+1) }},
+		{"TestScaleUpBrokerStatefulSets", func(t *testing.T) { scaleUpBrokerStatefulSets(t, plan) }},
+		{"TestWaitForNewBrokersToStart", func(t *testing.T) { waitForNewBrokersToStart(t, plan) }},
+		{"TestScaleUpBrokersAndPartitions", func(t *testing.T) {
+			change = scaleUpBrokersAndPartitions(t, plan, start.PartitionsCount+2, start.ReplicationFactor)
+		}},
+		{"TestWaitForCombinedScalingComplete", func(t *testing.T) { waitForScalingComplete(t, "combined broker and partition scaling", change, 60) }},
+		{"TestSnapshotTopologyAfterScaling", func(t *testing.T) { snapshots.Snapshot(t, "after scaling") }},
+		{"TestVerifyScaledClusterTopology", func(t *testing.T) { verifyClusterTopology(t, plan.ClusterSize(), start.PartitionsCount+2) }},
+	})
+}
+
+// TestZeebeClusterScaleDownBrokers tests removing Zeebe brokers in a multi-region setup
+// Brings the brokers back to ZEEBE_CLUSTER_SIZE, the partition count can't be reduced
+// The scale up tests are relative to the topology they observe, so they can run again on the same environment
+// Reference: https://docs.camunda.io/docs/self-managed/components/orchestration-cluster/zeebe/operations/cluster-scaling/
+func TestZeebeClusterScaleDownBrokers(t *testing.T) {
+	t.Log("[CLUSTER SCALING TEST] Testing Zeebe broker scale down in multi-region mode 🚀")
+	requireCapabilities(t, versionHelpers.OrchestrationValues, versionHelpers.ActuatorCluster)
+
+	if globalImageTag != "" {
+		t.Log("[GLOBAL IMAGE TAG] Overwriting image tag for all Camunda images with " + globalImageTag)
+		baseHelmVars = helpers.OverwriteImageTag(baseHelmVars, globalImageTag)
 	}
+
+	var start kubectlHelpers.ClusterInfo
+	var change scalingChange
+	var plan zeebeHelpers.BrokerScalingPlan
+	var snapshots *zeebeHelpers.TopologyRecorder
+
+	// Runs the tests sequentially
+	runSteps(t, []testStep{
+		{"TestInitKubernetesHelpers", initKubernetesHelpers},
+		{"TestInitTopologyRecorder", func(*testing.T) { snapshots = newTopologyRecorder(t, "broker-scale-down") }},
+		{"TestObserveClusterTopology", func(t *testing.T) { start = observeClusterTopology(t) }},
+		{"TestSnapshotTopologyBeforeScaling", func(t *testing.T) { snapshots.Snapshot(t, "before scaling") }},
+		{"TestPlanBrokerScaling", func(t *testing.T) { plan = planBrokerScaling(t, clusterSize/regionCount) }},
+		{"TestRemoveBrokersFromCluster", func(t *testing.T) { change = removeBrokersFromCluster(t, plan) }},
+		{"TestWaitForBrokerRemovalComplete", func(t *testing.T) { waitForScalingComplete(t, "broker removal", change, 60) }},
+		{"TestVerifyPartitionsMovedOffBrokers", func(t *testing.T) { verifyBrokersRemoved(t, plan.BrokersToRemove) }},
+		{"TestScaleDownBrokerStatefulSets", func(t *testing.T) { scaleDownBrokerStatefulSets(t, plan) }},
+		{"TestWaitForRemovedBrokersToStop", func(t *testing.T) { waitForRemovedBrokersToStop(t, plan) }},
+		{"TestSnapshotTopologyAfterScaling", func(t *testing.T) { snapshots.Snapshot(t, "after scaling") }},
+		{"TestDeleteOrphanedBrokerPVCs", func(t *testing.T) { deleteOrphanedBrokerPVCs(t, plan.TargetPerRegion) }},
+		{"TestVerifyScaledDownTopology", func(t *testing.T) { verifyClusterTopology(t, plan.ClusterSize(), start.PartitionsCount) }},
+	})
 }
 
 // Helper functions for cluster scaling tests
@@ -107,7 +154,7 @@ func verifyClusterTopology(t *testing.T, clusterSizeExpected, partitionCountExpe
 	t.Helper()
 	t.Logf("[SCALING] Verifying cluster topology: expecting %d brokers and %d partitions 🔍", clusterSizeExpected, partitionCountExpected)
 
-	clusterInfo := kubectlHelpers.GetClusterTopology(t, &primary.KubectlNamespace)
+	clusterInfo := kubectlHelpers.GetClusterTopology(t, &clusters[0].KubectlNamespace)
 	require.Equal(t, clusterSizeExpected, clusterInfo.ClusterSize, "Expected %d brokers", clusterSizeExpected)
 	require.Equal(t, partitionCountExpected, clusterInfo.PartitionsCount, "Expected %d partitions", partitionCountExpected)
 
@@ -115,168 +162,227 @@ func verifyClusterTopology(t *testing.T, clusterSizeExpected, partitionCountExpe
 		clusterInfo.ClusterSize, clusterInfo.PartitionsCount, clusterInfo.ReplicationFactor)
 }
 
-// scaleUpBrokerStatefulSets scales the Zeebe StatefulSets via Helm upgrade by setting orchestration.clusterSize
-// This approach is used when kubectl scale permissions are not available
-func scaleUpBrokerStatefulSets(t *testing.T, replicasPerRegion int) {
+// observeClusterTopology returns the topology the scaling starts from, the brokers have to be spread evenly across the regions
+func observeClusterTopology(t *testing.T) kubectlHelpers.ClusterInfo {
 	t.Helper()
-	totalClusterSize := replicasPerRegion * 2 // Total brokers across both regions
-	t.Logf("[SCALING] Scaling up Zeebe StatefulSets to %d replicas per region (%d total) via kubectl 🚀", replicasPerRegion, totalClusterSize)
 
-	replicasArg := fmt.Sprintf("--replicas=%d", replicasPerRegion)
+	clusterInfo := kubectlHelpers.GetClusterTopology(t, &clusters[0].KubectlNamespace)
+	require.Zero(t, clusterInfo.ClusterSize%regionCount, "Expected the %d brokers to be spread evenly across %d regions", clusterInfo.ClusterSize, regionCount)
 
-	t.Logf("[SCALING] Scaling primary region StatefulSet to %d replicas", replicasPerRegion)
-	k8s.RunKubectl(t, &primary.KubectlNamespace, "scale", "statefulset/camunda-zeebe", replicasArg)
+	t.Logf("[SCALING] Starting from %d brokers, %d partitions, replication factor %d",
+		clusterInfo.ClusterSize, clusterInfo.PartitionsCount, clusterInfo.ReplicationFactor)
+	return clusterInfo
+}
 
-	t.Logf("[SCALING] Scaling secondary region StatefulSet to %d replicas", replicasPerRegion)
-	k8s.RunKubectl(t, &secondary.KubectlNamespace, "scale", "statefulset/camunda-zeebe", replicasArg)
+// brokersPerRegion returns the number of brokers each region runs in the topology
+func brokersPerRegion(clusterInfo kubectlHelpers.ClusterInfo) int {
+	return clusterInfo.ClusterSize / regionCount
+}
 
-	t.Log("[SCALING] Helm upgrades completed, StatefulSets will scale up")
+// planBrokerScaling computes the pod indexes and broker ids to get to brokersPerRegion from the current topology
+func planBrokerScaling(t *testing.T, brokersPerRegion int) zeebeHelpers.BrokerScalingPlan {
+	t.Helper()
+
+	clusterInfo := kubectlHelpers.GetClusterTopology(t, &clusters[0].KubectlNamespace)
+	plan, err := zeebeHelpers.PlanBrokerScaling(clusterInfo, regionCount, brokersPerRegion)
+	require.NoError(t, err, "Failed to plan scaling to %d brokers per region", brokersPerRegion)
+
+	t.Logf("[SCALING] Broker scaling plan:\n%s", plan)
+	return plan
+}
+
+// scaleUpBrokerStatefulSets grows the Zeebe StatefulSets in all regions to the replicas of the plan via kubectl scale
+// The new brokers only join the cluster once they are added through the actuator
+func scaleUpBrokerStatefulSets(t *testing.T, plan zeebeHelpers.BrokerScalingPlan) {
+	t.Helper()
+	replicasPerRegion := plan.TargetPerRegion
+	t.Logf("[SCALING] Scaling up Zeebe StatefulSets to %d replicas per region (%d total) via kubectl 🚀", replicasPerRegion, plan.ClusterSize())
+
+	replicasArg := fmt.Sprintf("--replicas=%d", replicasPerRegion)
+
+	for region := range clusters {
+		t.Logf("[SCALING] Scaling region %d StatefulSet to %d replicas", region, replicasPerRegion)
+		k8s.RunKubectl(t, &clusters[region].KubectlNamespace, "scale", "statefulset/camunda-zeebe", replicasArg)
+	}
+
+	t.Log("[SCALING] StatefulSets scaled, the new broker pods are starting")
 }
 
 // waitForNewBrokersToStart waits for the new broker pods to have status=Running
-// startIndex is the first new pod index, count is how many new pods to wait for
-func waitForNewBrokersToStart(t *testing.T, startIndex, count int) {
+func waitForNewBrokersToStart(t *testing.T, plan zeebeHelpers.BrokerScalingPlan) {
 	t.Helper()
-	t.Logf("[SCALING] Waiting for %d new broker pods starting at index %d to be Running 🕐", count, startIndex)
+	t.Logf("[SCALING] Waiting for new broker pods with indexes %v to be Running 🕐", plan.NewPodIndexes)
 
-	for i := startIndex; i < startIndex+count; i++ {
+	for _, i := range plan.NewPodIndexes {
 		podName := fmt.Sprintf("camunda-zeebe-%d", i)
-		waitForPodRunning(t, &primary.KubectlNamespace, podName, "primary")
-		waitForPodRunning(t, &secondary.KubectlNamespace, podName, "secondary")
+		for region := range clusters {
+			waitForPodRunning(t, &clusters[region].KubectlNamespace, podName, region)
+		}
 	}
 
 	t.Log("[SCALING] All new broker pods are Running")
 }
 
 // waitForPodRunning waits for a specific pod to reach Running status
-func waitForPodRunning(t *testing.T, kubectlOptions *k8s.KubectlOptions, podName, regionName string) {
+func waitForPodRunning(t *testing.T, kubectlOptions *k8s.KubectlOptions, podName string, region int) {
 	t.Helper()
 
 	maxRetries := 20
 	retryInterval := 15 * time.Second
 
-	t.Logf("[SCALING] Waiting for %s region pod %s to be Running", regionName, podName)
+	t.Logf("[SCALING] Waiting for region %d pod %s to be Running", region, podName)
 	for retry := 0; retry < maxRetries; retry++ {
 		pod := k8s.GetPod(t, kubectlOptions, podName)
 		if pod.Status.Phase == "Running" {
-			t.Logf("[SCALING] %s region pod %s is Running", regionName, podName)
+			t.Logf("[SCALING] Region %d pod %s is Running", region, podName)
 			return
 		}
 		if retry == maxRetries-1 {
-			t.Fatalf("[SCALING] %s region pod %s did not reach Running status (current: %s)", regionName, podName, pod.Status.Phase)
+			t.Fatalf("[SCALING] Region %d pod %s did not reach Running status (current: %s)", region, podName, pod.Status.Phase)
 		}
-		t.Logf("[SCALING] %s region pod %s status: %s (attempt %d/%d)", regionName, podName, pod.Status.Phase, retry+1, maxRetries)
+		t.Logf("[SCALING] Region %d pod %s status: %s (attempt %d/%d)", region, podName, pod.Status.Phase, retry+1, maxRetries)
 		time.Sleep(retryInterval)
 	}
 }
 
-// waitForScalingComplete polls the cluster status until scaling is complete
+// waitForScalingComplete follows the cluster change until it is completed, cancelling and rolling it back on timeout
 // operationName is used for logging, maxRetries controls the timeout (each retry waits 15 seconds)
-func waitForScalingComplete(t *testing.T, operationName string, maxRetries int) {
+func waitForScalingComplete(t *testing.T, operationName string, change scalingChange, maxRetries int) {
 	t.Helper()
 	t.Logf("[SCALING] Waiting for %s to complete 🕐", operationName)
 
-	service := k8s.GetService(t, &primary.KubectlNamespace, "camunda-zeebe-gateway")
-	require.Equal(t, "camunda-zeebe-gateway", service.Name)
-
-	endpoint, closeFn := kubectlHelpers.NewServiceTunnelWithRetry(t, &primary.KubectlNamespace, "camunda-zeebe-gateway", 0, 9600, 5, 15*time.Second)
+	client, closeFn := zeebeHelpers.NewActuatorTunnel(t, &clusters[0].KubectlNamespace)
 	defer closeFn()
 
-	for i := 0; i < maxRetries; i++ {
-		res, body := helpers.HttpRequest(t, "GET", fmt.Sprintf("http://%s/actuator/cluster", endpoint), nil)
-		require.NotNil(t, res, "Failed to query cluster status")
-		require.Equal(t, 200, res.StatusCode)
+	zeebeHelpers.WaitForClusterChangeOrRollback(t, client, change.Id, change.Request, operationName, maxRetries, 15*time.Second)
+	t.Logf("[SCALING] %s completed successfully", operationName)
+}
 
-		// Check if there's no pending change
-		if !strings.Contains(body, "pendingChange") {
-			t.Logf("[SCALING] %s completed successfully", operationName)
-			require.Contains(t, body, "COMPLETED", "Expected lastChange status to be COMPLETED")
-			return
-		}
+// addNewBrokersToCluster sends API request to add new brokers to the cluster
+func addNewBrokersToCluster(t *testing.T, plan zeebeHelpers.BrokerScalingPlan) scalingChange {
+	t.Helper()
+	t.Logf("[SCALING] Adding new brokers %v to the cluster via API 🚀", plan.BrokersToAdd)
+	require.NotEmpty(t, plan.BrokersToAdd, "Expected the scaling plan to add brokers")
+
+	return patchClusterTopology(t, plan.Change(), "broker addition")
+}
+
+// scaleUpPartitions sends API request to increase partition count
+func scaleUpPartitions(t *testing.T, partitionCount, replicationFactor int) scalingChange {
+	t.Helper()
+	t.Logf("[SCALING] Scaling up to %d partitions with replication factor %d 🚀", partitionCount, replicationFactor)
 
-		t.Logf("[SCALING] %s in progress... (attempt %d/%d)", operationName, i+1, maxRetries)
-		time.Sleep(15 * time.Second)
+	change := zeebeHelpers.ClusterChangeRequest{
+		Partitions: &zeebeHelpers.PartitionsChange{Count: partitionCount, ReplicationFactor: replicationFactor},
 	}
+	return patchClusterTopology(t, change, "partition scaling")
+}
 
-	t.Fatalf("[SCALING] %s did not complete within the expected time", operationName)
+// scaleUpBrokersAndPartitions sends API request to scale both brokers and partitions
+func scaleUpBrokersAndPartitions(t *testing.T, plan zeebeHelpers.BrokerScalingPlan, partitionCount, replicationFactor int) scalingChange {
+	t.Helper()
+	t.Logf("[SCALING] Scaling up brokers %v and partitions to %d simultaneously 🚀", plan.BrokersToAdd, partitionCount)
+	require.NotEmpty(t, plan.BrokersToAdd, "Expected the scaling plan to add brokers")
+
+	change := plan.Change()
+	change.Partitions = &zeebeHelpers.PartitionsChange{Count: partitionCount, ReplicationFactor: replicationFactor}
+	return patchClusterTopology(t, change, "combined broker and partition scaling")
 }
 
-// addNewBrokersToCluster sends API request to add new brokers to the cluster
-func addNewBrokersToCluster(t *testing.T, brokersToAdd []int) {
+// removeBrokersFromCluster sends API request to remove brokers from the cluster, moving their partitions to the remaining brokers
+func removeBrokersFromCluster(t *testing.T, plan zeebeHelpers.BrokerScalingPlan) scalingChange {
 	t.Helper()
-	t.Logf("[SCALING] Adding new brokers %v to the cluster via API 🚀", brokersToAdd)
+	t.Logf("[SCALING] Removing brokers %v from the cluster via API 🚀", plan.BrokersToRemove)
+	require.NotEmpty(t, plan.BrokersToRemove, "Expected the scaling plan to remove brokers")
 
-	payload := map[string]interface{}{
-		"brokers": map[string]interface{}{
-			"add": brokersToAdd,
-		},
+	return patchClusterTopology(t, plan.Change(), "broker removal")
+}
+
+// verifyBrokersRemoved verifies that the removed brokers are neither part of the cluster topology nor host any partition
+func verifyBrokersRemoved(t *testing.T, removedBrokers []int) {
+	t.Helper()
+	t.Logf("[SCALING] Verifying brokers %v no longer host any partitions 🔍", removedBrokers)
+
+	client, closeFn := zeebeHelpers.NewActuatorTunnel(t, &clusters[0].KubectlNamespace)
+	defer closeFn()
+
+	topology, err := client.GetCluster()
+	require.NoError(t, err, "Failed to query cluster topology")
+
+	for _, brokerId := range removedBrokers {
+		broker := topology.Broker(brokerId)
+		require.Nil(t, broker, "Expected broker %d to be removed from the cluster topology", brokerId)
 	}
-	patchClusterTopology(t, payload, "broker addition")
+
+	t.Log("[SCALING] Partitions moved off the removed brokers")
 }
 
-// scaleUpPartitions sends API request to increase partition count
-func scaleUpPartitions(t *testing.T, partitionCount, replicationFactor int) {
+// scaleDownBrokerStatefulSets shrinks the Zeebe StatefulSets in all regions to the given replicas
+// Must only be called once the brokers of the removed pods left the cluster, otherwise partitions lose replicas
+func scaleDownBrokerStatefulSets(t *testing.T, plan zeebeHelpers.BrokerScalingPlan) {
 	t.Helper()
-	t.Logf("[SCALING] Scaling up to %d partitions with replication factor %d 🚀", partitionCount, replicationFactor)
+	replicasPerRegion := plan.TargetPerRegion
+	t.Logf("[SCALING] Scaling down Zeebe StatefulSets to %d replicas per region (%d total) via kubectl 🚀", replicasPerRegion, plan.ClusterSize())
 
-	payload := map[string]interface{}{
-		"partitions": map[string]interface{}{
-			"count":             partitionCount,
-			"replicationFactor": replicationFactor,
-		},
+	replicasArg := fmt.Sprintf("--replicas=%d", replicasPerRegion)
+
+	for region := range clusters {
+		t.Logf("[SCALING] Scaling region %d StatefulSet to %d replicas", region, replicasPerRegion)
+		k8s.RunKubectl(t, &clusters[region].KubectlNamespace, "scale", "statefulset/camunda-zeebe", replicasArg)
 	}
-	patchClusterTopology(t, payload, "partition scaling")
 }
 
-// scaleUpBrokersAndPartitions sends API request to scale both brokers and partitions
-func scaleUpBrokersAndPartitions(t *testing.T, brokersToAdd []int, partitionCount, replicationFactor int) {
+// waitForRemovedBrokersToStop waits for the broker pods removed by the scale down to be deleted
+func waitForRemovedBrokersToStop(t *testing.T, plan zeebeHelpers.BrokerScalingPlan) {
 	t.Helper()
-	t.Logf("[SCALING] Scaling up brokers %v and partitions to %d simultaneously 🚀", brokersToAdd, partitionCount)
-
-	payload := map[string]interface{}{
-		"brokers": map[string]interface{}{
-			"add": brokersToAdd,
-		},
-		"partitions": map[string]interface{}{
-			"count":             partitionCount,
-			"replicationFactor": replicationFactor,
-		},
+	t.Logf("[SCALING] Waiting for removed broker pods with indexes %v to be deleted 🕐", plan.RemovedPodIndexes)
+
+	for _, i := range plan.RemovedPodIndexes {
+		podName := fmt.Sprintf("camunda-zeebe-%d", i)
+		for region := range clusters {
+			kubectlHelpers.WaitForPodDeleted(t, &clusters[region].KubectlNamespace, podName, 20, 15*time.Second)
+		}
 	}
-	patchClusterTopology(t, payload, "combined broker and partition scaling")
+
+	t.Log("[SCALING] All removed broker pods are deleted")
 }
 
-// patchClusterTopology sends a PATCH request to the Zeebe gateway cluster actuator endpoint
-// It performs a dry run first, then executes the actual scaling operation
-func patchClusterTopology(t *testing.T, payload map[string]interface{}, operationName string) {
+// deleteOrphanedBrokerPVCs deletes the PVCs of the removed broker pods, if DELETE_ORPHANED_PVCS is enabled
+// Kept PVCs would be picked up with stale data by the next scale up
+func deleteOrphanedBrokerPVCs(t *testing.T, replicasPerRegion int) {
 	t.Helper()
 
-	service := k8s.GetService(t, &primary.KubectlNamespace, "camunda-zeebe-gateway")
-	require.Equal(t, "camunda-zeebe-gateway", service.Name)
+	if !deleteOrphanedPVCs {
+		t.Skip("[SCALING] Keeping orphaned broker PVCs as DELETE_ORPHANED_PVCS is not enabled")
+	}
 
-	endpoint, closeFn := kubectlHelpers.NewServiceTunnelWithRetry(t, &primary.KubectlNamespace, "camunda-zeebe-gateway", 0, 9600, 5, 15*time.Second)
-	defer closeFn()
+	t.Log("[SCALING] Deleting orphaned broker PVCs 🧹")
+	var deleted []string
+	for region := range clusters {
+		deleted = append(deleted, kubectlHelpers.DeleteOrphanedStatefulSetPVCs(t, &clusters[region].KubectlNamespace, "camunda-zeebe", replicasPerRegion)...)
+	}
+	t.Logf("[SCALING] Deleted %d orphaned broker PVCs", len(deleted))
+}
 
-	payloadBytes, err := json.Marshal(payload)
-	require.NoError(t, err, "Failed to marshal payload")
+// scalingChange is a submitted cluster change together with the request, needed to roll it back
+type scalingChange struct {
+	Id      int64
+	Request zeebeHelpers.ClusterChangeRequest
+}
+
+// patchClusterTopology sends a PATCH request to the Zeebe gateway cluster actuator endpoint
+// It performs a dry run first, logs the plan, then executes the actual scaling operation and asserts it matches the dry run
+// Returns the change to follow and, if needed, roll back
+func patchClusterTopology(t *testing.T, change zeebeHelpers.ClusterChangeRequest, operationName string) scalingChange {
+	t.Helper()
+
+	client, closeFn := zeebeHelpers.NewActuatorTunnel(t, &clusters[0].KubectlNamespace)
+	defer closeFn()
 
-	// Now perform the actual scaling
 	t.Logf("[SCALING] Executing %s", operationName)
-	res, body := helpers.HttpRequest(
-		t,
-		"PATCH",
-		fmt.Sprintf("http://%s/actuator/cluster", endpoint),
-		bytes.NewBuffer(payloadBytes),
-	)
-	require.NotNil(t, res, "Failed to create request")
-	require.Equal(t, 202, res.StatusCode, "Expected 202 Accepted status")
-	require.NotEmpty(t, body)
-	require.Contains(t, body, "plannedChanges")
-	require.Contains(t, body, "changeId")
-
-	// Parse and log the change ID
-	var response map[string]interface{}
-	err = json.Unmarshal([]byte(body), &response)
-	require.NoError(t, err)
-	changeId := response["changeId"]
-	t.Logf("[SCALING] %s initiated with changeId: %v", operationName, changeId)
+	response := zeebeHelpers.PlanAndApplyClusterChange(t, client, change, nil)
+	require.NotEmpty(t, response.PlannedChanges, "Expected planned changes for %s", operationName)
+
+	t.Logf("[SCALING] %s initiated with changeId: %d", operationName, response.ChangeId)
+	return scalingChange{Id: response.ChangeId, Request: change}
 }
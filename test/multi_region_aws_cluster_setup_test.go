@@ -20,8 +20,10 @@ func TestSetupTerraform(t *testing.T) {
 
 func TestAWSKubeConfigCreation(t *testing.T) {
 	t.Log("[KUBECONFIG] Creating kubeconfig files 🚀")
-	awsHelpers.GenerateAWSKubeConfig(t, clusterName, awsProfile, "eu-west-2", "london")
-	awsHelpers.GenerateAWSKubeConfig(t, clusterName, awsProfile, "eu-west-3", "paris")
+	for region := range regionCount {
+		cloudRegion, name := awsRegion(region)
+		awsHelpers.GenerateAWSKubeConfig(t, clusterName, awsProfile, cloudRegion, name)
+	}
 }
 
 func TestTeardownTerraform(t *testing.T) {
@@ -31,27 +33,25 @@ func TestTeardownTerraform(t *testing.T) {
 
 func TestAWSKubeConfigRemoval(t *testing.T) {
 	t.Log("[KUBECONFIG] Removing kubeconfig files 🗑️")
-	awsHelpers.TestRemoveKubeConfig(t, "london")
-	awsHelpers.TestRemoveKubeConfig(t, "paris")
+	for region := range regionCount {
+		_, name := awsRegion(region)
+		awsHelpers.TestRemoveKubeConfig(t, name)
+	}
 }
 
 func TestClusterCleanup(t *testing.T) {
 	t.Log("[CLEANUP] Cleaning up resources 🧹")
 
-	for _, testFuncs := range []struct {
-		name  string
-		tfunc func(*testing.T)
-	}{
+	runSteps(t, []testStep{
 		{"TestInitKubernetesHelpers", initKubernetesHelpers},
 		{"TestCleanupKubernetes", cleanupKubernetes},
-	} {
-		t.Run(testFuncs.name, testFuncs.tfunc)
-	}
+	})
 }
 
 func cleanupKubernetes(t *testing.T) {
 	t.Log("[K8S CLEANUP] Cleaning up Kubernetes resources 🧹")
 
-	k8s.RunKubectl(t, &primary.KubectlSystem, "delete", "--ignore-not-found=true", "service", "internal-dns-lb")
-	k8s.RunKubectl(t, &secondary.KubectlSystem, "delete", "--ignore-not-found=true", "service", "internal-dns-lb")
+	for region := range clusters {
+		k8s.RunKubectl(t, &clusters[region].KubectlSystem, "delete", "--ignore-not-found=true", "service", "internal-dns-lb")
+	}
 }
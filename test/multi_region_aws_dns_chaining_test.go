@@ -1,6 +1,7 @@
 package test
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,20 +16,27 @@ import (
 
 // Used for creating the global core dns configmap for all versions
 // each is a comma separated string "namespace1,namespace2" value originates from "c8_namespace_parser.sh"
-var (
-	primaryNamespaceArr           = helpers.GetEnv("CLUSTER_0_NAMESPACE_ARR", "")
-	primaryNamespaceFailoverArr   = helpers.GetEnv("CLUSTER_0_NAMESPACE_FAILOVER_ARR", "")
-	secondaryNamespaceArr         = helpers.GetEnv("CLUSTER_1_NAMESPACE_ARR", "")
-	secondaryNamespaceFailoverArr = helpers.GetEnv("CLUSTER_1_NAMESPACE_FAILOVER_ARR", "")
-)
+// CLUSTER_<regionId>_NAMESPACE_ARR and CLUSTER_<regionId>_NAMESPACE_FAILOVER_ARR combined
+func regionNamespaces(region int) []string {
+	return append(
+		strings.Split(helpers.GetEnv(fmt.Sprintf("CLUSTER_%d_NAMESPACE_ARR", region), ""), ","),
+		strings.Split(helpers.GetEnv(fmt.Sprintf("CLUSTER_%d_NAMESPACE_FAILOVER_ARR", region), ""), ",")...,
+	)
+}
+
+func kubeConfigPath() string {
+	wd, _ := os.Getwd()
+	var paths []string
+	for _, config := range kubeConfigs() {
+		paths = append(paths, filepath.Join(wd, config))
+	}
+	return strings.Join(paths, string(os.PathListSeparator))
+}
 
 func TestAWSDNSChaining(t *testing.T) {
 	t.Log("[DNS CHAINING] Running tests for AWS EKS Multi-Region 🚀")
 
-	for _, testFuncs := range []struct {
-		name  string
-		tfunc func(*testing.T)
-	}{
+	runSteps(t, []testStep{
 		{"TestInitKubernetesHelpers", initKubernetesHelpers},
 		{"TestClusterReadyCheck", clusterReadyCheck},
 		// AWS DNS Chaining and cross cluster communication
@@ -36,9 +44,7 @@ func TestAWSDNSChaining(t *testing.T) {
 		{"TestApplyDnsChaining", applyDnsChaining},
 		{"TestCoreDNSReload", testCoreDNSReload},
 		{"TestCrossClusterCommunicationWithDNS", testCrossClusterCommunicationWithDNS},
-	} {
-		t.Run(testFuncs.name, testFuncs.tfunc)
-	}
+	})
 }
 
 func TestClusterPrerequisites(t *testing.T) {
@@ -56,40 +62,39 @@ func TestClusterPrerequisites(t *testing.T) {
 	t.Run("TestCreateAllNamespacesAndSecrets", func(t *testing.T) {
 		t.Log("[K8S] Creating all namespaces and secrets 🚀")
 
-		// Combine primary and failover namespaces.
-		allPrimaryNamespaces := append(
-			strings.Split(primaryNamespaceArr, ","),
-			strings.Split(primaryNamespaceFailoverArr, ",")...,
-		)
-		allSecondaryNamespaces := append(
-			strings.Split(secondaryNamespaceArr, ","),
-			strings.Split(secondaryNamespaceFailoverArr, ",")...,
-		)
-
-		// Ensure both arrays have the same length.
-		if len(allPrimaryNamespaces) != len(allSecondaryNamespaces) {
-			t.Fatal("Primary and secondary namespace arrays must have the same length")
+		namespaces := make([][]string, len(clusters))
+		for region := range clusters {
+			namespaces[region] = regionNamespaces(region)
 		}
 
-		// Iterate over namespaces and set environment variables appropriately.
-		for i := range allPrimaryNamespaces {
-			if helpers.IsTeleportEnabled() {
-				os.Setenv("KUBECONFIG", "./kubeconfig")
-				t.Logf("Primary Namespace: %s, Secondary Namespace: %s", allPrimaryNamespaces[i], allSecondaryNamespaces[i])
-			} else {
-				os.Setenv("KUBECONFIG", kubeConfigPrimary+":"+kubeConfigSecondary)
-				os.Setenv("CLUSTER_0", primary.ClusterName)
-				os.Setenv("CAMUNDA_NAMESPACE_0", allPrimaryNamespaces[i])
-				os.Setenv("CLUSTER_1", secondary.ClusterName)
-				os.Setenv("CAMUNDA_NAMESPACE_1", allSecondaryNamespaces[i])
+		// Ensure all arrays have the same length.
+		for region := range clusters {
+			if len(namespaces[region]) != len(namespaces[0]) {
+				t.Fatalf("Namespace arrays of region 0 and %d must have the same length", region)
 			}
+		}
 
-			shell.RunCommand(t, shell.Command{
-				Command: "sh",
-				Args: []string{
-					"../aws/dual-region/scripts/create_elasticsearch_secrets.sh",
-				},
-			})
+		// The script handles a pair of regions, pair region 0 with every other region
+		for i := range namespaces[0] {
+			for region := 1; region < len(clusters); region++ {
+				if helpers.IsTeleportEnabled() {
+					os.Setenv("KUBECONFIG", "./kubeconfig")
+					t.Logf("Region 0 Namespace: %s, Region %d Namespace: %s", namespaces[0][i], region, namespaces[region][i])
+				} else {
+					os.Setenv("KUBECONFIG", strings.Join(kubeConfigs(), ":"))
+					os.Setenv("CLUSTER_0", clusters[0].ClusterName)
+					os.Setenv("CAMUNDA_NAMESPACE_0", namespaces[0][i])
+					os.Setenv("CLUSTER_1", clusters[region].ClusterName)
+					os.Setenv("CAMUNDA_NAMESPACE_1", namespaces[region][i])
+				}
+
+				shell.RunCommand(t, shell.Command{
+					Command: "sh",
+					Args: []string{
+						"../aws/dual-region/scripts/create_elasticsearch_secrets.sh",
+					},
+				})
+			}
 		}
 	})
 
@@ -98,18 +103,17 @@ func TestClusterPrerequisites(t *testing.T) {
 }
 
 func createStorageClass(t *testing.T) {
-	t.Log("[STORAGE CLASS] Creating Storage Class for both clusters 🚀")
+	t.Log("[STORAGE CLASS] Creating Storage Class for all clusters 🚀")
 
 	if helpers.IsTeleportEnabled() {
 		t.Logf("Skipping Storage Class creation when Teleport is enabled")
 		return
 	}
 
-	wd, _ := os.Getwd()
-	os.Setenv("KUBECONFIG",
-		filepath.Join(wd, kubeConfigPrimary)+string(os.PathListSeparator)+filepath.Join(wd, kubeConfigSecondary))
-	os.Setenv("CLUSTER_0", primary.ClusterName)
-	os.Setenv("CLUSTER_1", secondary.ClusterName)
+	os.Setenv("KUBECONFIG", kubeConfigPath())
+	for region := range clusters {
+		os.Setenv(fmt.Sprintf("CLUSTER_%d", region), clusters[region].ClusterName)
+	}
 
 	shell.RunCommand(t, shell.Command{
 		Command:    "sh",
@@ -129,36 +133,40 @@ func createStorageClass(t *testing.T) {
 
 func clusterReadyCheck(t *testing.T) {
 	t.Log("[CLUSTER CHECK] Checking if clusters are ready 🚦")
-	awsHelpers.ClusterReadyCheck(t, primary)
-	awsHelpers.ClusterReadyCheck(t, secondary)
+	for region := range clusters {
+		awsHelpers.ClusterReadyCheck(t, clusters[region])
+	}
 }
 
 func testCrossClusterCommunication(t *testing.T) {
 	t.Log("[CROSS CLUSTER] Testing cross-cluster communication with IPs 📡")
 	t.Run("TestInitKubernetesHelpers", initKubernetesHelpers)
 
-	kubectlHelpers.CrossClusterCommunication(t, false, k8sManifests, primary, secondary, kubeConfigPrimary, kubeConfigSecondary)
+	kubectlHelpers.CrossClusterCommunication(t, false, k8sManifests, clusters, kubeConfigs())
 }
 
 func applyDnsChaining(t *testing.T) {
 	t.Log("[DNS CHAINING] Applying DNS chaining 📡")
-	awsHelpers.CreateLoadBalancers(t, primary, k8sManifests)
-	awsHelpers.CreateLoadBalancers(t, secondary, k8sManifests)
-	allPrimaryNamespaces := primaryNamespaceArr + "," + primaryNamespaceFailoverArr
-	allSecondaryNamespaces := secondaryNamespaceArr + "," + secondaryNamespaceFailoverArr
-	os.Setenv("KUBECONFIG", kubeConfigPrimary+":"+kubeConfigSecondary)
-	awsHelpers.DNSChaining(t, primary, secondary, k8sManifests, allPrimaryNamespaces, allSecondaryNamespaces)
+	lbIPs := make([][]string, len(clusters))
+	namespaces := make([][]string, len(clusters))
+	for region := range clusters {
+		lbIPs[region] = awsHelpers.CreateLoadBalancers(t, clusters[region], k8sManifests)
+		namespaces[region] = regionNamespaces(region)
+	}
+	os.Setenv("KUBECONFIG", strings.Join(kubeConfigs(), ":"))
+	awsHelpers.DNSChaining(t, clusters, lbIPs, k8sManifests, namespaces)
 	os.Unsetenv("KUBECONFIG")
 }
 
 func testCoreDNSReload(t *testing.T) {
 	t.Logf("[COREDNS RELOAD] Checking for CoreDNS reload 🔄")
-	kubectlHelpers.CheckCoreDNSReload(t, &primary.KubectlSystem)
-	kubectlHelpers.CheckCoreDNSReload(t, &secondary.KubectlSystem)
+	for region := range clusters {
+		kubectlHelpers.CheckCoreDNSReload(t, &clusters[region].KubectlSystem)
+	}
 }
 
 func testCrossClusterCommunicationWithDNS(t *testing.T) {
 	t.Log("[CROSS CLUSTER] Testing cross-cluster communication with DNS 📡")
 	t.Run("TestInitKubernetesHelpers", initKubernetesHelpers)
-	kubectlHelpers.CrossClusterCommunication(t, false, k8sManifests, primary, secondary, kubeConfigPrimary, kubeConfigSecondary)
+	kubectlHelpers.CrossClusterCommunication(t, false, k8sManifests, clusters, kubeConfigs())
 }
@@ -0,0 +1,16 @@
+package test
+
+import "testing"
+
+// testStep is a named step of an integration test
+type testStep struct {
+	name  string
+	tfunc func(*testing.T)
+}
+
+// runSteps runs the steps one after another as subtests
+func runSteps(t *testing.T, steps []testStep) {
+	for _, step := range steps {
+		t.Run(step.name, step.tfunc)
+	}
+}